@@ -3,16 +3,21 @@ package main
 import (
 	"avito-winter-2025/config"
 	"avito-winter-2025/internal/delivery"
+	"avito-winter-2025/internal/entity"
 	"avito-winter-2025/internal/repo"
 	"avito-winter-2025/internal/usecase"
+	"avito-winter-2025/internal/utils/ratelimit"
 	"avito-winter-2025/internal/utils/token"
+	"avito-winter-2025/internal/worker"
 	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -41,39 +46,168 @@ func main() {
 	if err != nil {
 		logger.Error("Failed read jwt duration from docker-compose", zap.String("error", err.Error()))
 	}
+	keys := []token.Key{}
+	for _, k := range cfg.Auth.Signing.Keys {
+		key, err := token.LoadKey(k.ID, k.File, k.ActiveFrom, k.RetireAt)
+		if err != nil {
+			logger.Fatal("Failed to load signing key", zap.String("kid", k.ID), zap.String("error", err.Error()))
+		}
+		keys = append(keys, key)
+	}
+	keySet, err := token.NewKeySet(keys...)
+	if err != nil {
+		logger.Fatal("Invalid signing keys", zap.String("error", err.Error()))
+	}
+	if len(keys) > 0 {
+		if _, err := keySet.Signing(); err != nil {
+			logger.Fatal("Invalid signing keys", zap.String("error", err.Error()))
+		}
+		jwt.Keys = keySet
+		if !cfg.Auth.Signing.AcceptHS256 {
+			jwt.Secret = nil
+		}
+	}
+
+	policies := []entity.AllowancePolicy{}
+	for _, p := range cfg.Allowance.Policies {
+		policy := entity.AllowancePolicy{
+			Name:              p.Name,
+			Amount:            p.Amount,
+			PeriodMonths:      p.PeriodMonths,
+			ExpireAfterMonths: p.ExpireAfterMonths,
+			Role:              p.Role,
+		}
+		if !policy.Valid() {
+			logger.Fatal("Invalid allowance policy", zap.String("policy", p.Name))
+		}
+		policies = append(policies, policy)
+	}
 
 	userRepo := repo.NewUser(db)
-	coinRepo := repo.NewCoin(db)
+	coinRepo := repo.NewCoin(db, logger)
 	merchRepo := repo.NewMerch(db)
+	idempotencyRepo := repo.NewIdempotency(db)
+	refundRepo := repo.NewRefund(db)
+	loginAttemptRepo := repo.NewLoginAttempt(db)
+	tokenRepo := repo.NewToken(db)
+	scheduledRepo := repo.NewScheduled(db)
+	coinRequestRepo := repo.NewCoinRequest(db, logger)
+	escrowRepo := repo.NewEscrow(db, logger)
+	allowanceRepo := repo.NewAllowance(db)
 
-	userUsecase := usecase.NewUser(userRepo)
+	lockout := cfg.Auth.Lockout
+	userUsecase := usecase.NewUser(userRepo, loginAttemptRepo, entity.LockoutPolicy{
+		MaxFailures:   lockout.MaxFailures,
+		MaxIPFailures: lockout.MaxIPFailures,
+		Window:        lockout.Window,
+		LockDuration:  lockout.LockDuration,
+		BaseDelay:     lockout.BaseDelay,
+		MaxDelay:      lockout.MaxDelay,
+	}, cfg.Auth.AutoRegister)
 	coinUsecase := usecase.NewCoin(coinRepo, userRepo)
 	merchUsecase := usecase.NewMerch(merchRepo, coinRepo)
+	idempotencyUsecase := usecase.NewIdempotency(idempotencyRepo, cfg.Idempotency.TTL)
+	merchAdminUsecase := usecase.NewMerchAdmin(merchRepo)
+	refundUsecase := usecase.NewRefund(refundRepo, cfg.Refund.Window)
+	tokenUsecase := usecase.NewToken(tokenRepo, userRepo, jwt, cfg.Auth.RefreshTTL, cfg.Auth.RevocationSync)
+	passwordUsecase := usecase.NewPassword(userRepo, cfg.Auth.PasswordResetTTL)
+	scheduledUsecase := usecase.NewScheduled(scheduledRepo, userRepo, cfg.Scheduled.BatchSize)
+	coinRequestUsecase := usecase.NewCoinRequest(coinRequestRepo, userRepo, cfg.CoinRequest.TTL)
+	escrowUsecase := usecase.NewEscrow(escrowRepo, cfg.Escrow.Window, cfg.Escrow.BatchSize)
+	allowanceUsecase := usecase.NewAllowance(allowanceRepo, policies, cfg.Allowance.BatchSize)
 
-	authHandler := delivery.NewAuthHandler(userUsecase, logger, jwt)
-	coinHandler := delivery.NewCoinHandler(coinUsecase, userUsecase)
+	authHandler := delivery.NewAuthHandler(userUsecase, tokenUsecase, logger)
+	coinHandler := delivery.NewCoinHandler(coinUsecase, userUsecase, escrowUsecase)
 	shopHandler := delivery.NewShopHandler(merchUsecase, userUsecase, coinUsecase)
+	merchAdminHandler := delivery.NewMerchAdminHandler(merchAdminUsecase)
+	refundHandler := delivery.NewRefundHandler(refundUsecase)
+	jwksHandler := delivery.NewJWKSHandler(keySet)
+	passwordHandler := delivery.NewPasswordHandler(passwordUsecase, tokenUsecase, logger)
+	scheduledHandler := delivery.NewScheduledHandler(scheduledUsecase)
+	coinRequestHandler := delivery.NewCoinRequestHandler(coinRequestUsecase)
+	escrowHandler := delivery.NewEscrowHandler(escrowUsecase)
+	allowanceHandler := delivery.NewAllowanceHandler(allowanceUsecase)
+	idempotency := delivery.NewIdempotencyMiddleware(idempotencyUsecase, logger)
+	jwtMiddleware := delivery.NewJWTMiddleware(jwt, tokenUsecase)
+	rateLimiter := delivery.NewRateLimitMiddleware(ratelimit.NewMemory())
+	limit := func(route string) func(http.HandlerFunc) http.HandlerFunc {
+		rule := cfg.RateLimit[route]
+		return rateLimiter.Handle(route, ratelimit.Limit{Requests: rule.Requests, Per: rule.Per, Burst: rule.Burst})
+	}
 
-	r := mux.NewRouter().PathPrefix("/api").Subrouter()
+	root := mux.NewRouter()
+	root.HandleFunc("/.well-known/jwks.json", jwksHandler.Get).Methods(http.MethodGet)
+	r := root.PathPrefix("/api").Subrouter()
 
 	r.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
-	r.HandleFunc("/info", delivery.JWTMiddleware(shopHandler.GetInfo)).Methods(http.MethodGet)
-	r.HandleFunc("/sendCoin", delivery.JWTMiddleware(coinHandler.SendCoin)).Methods(http.MethodPost)
-	r.HandleFunc("/buy/{item}", delivery.JWTMiddleware(shopHandler.BuyMerch)).Methods(http.MethodGet)
-	r.HandleFunc("/auth", authHandler.Auth).Methods(http.MethodPost)
+	r.HandleFunc("/info", jwtMiddleware.Handle(shopHandler.GetInfo)).Methods(http.MethodGet)
+	r.HandleFunc("/sendCoin", jwtMiddleware.Handle(limit("send_coin")(idempotency.Handle(coinHandler.SendCoin)))).Methods(http.MethodPost)
+	r.HandleFunc("/sendCoin/batch", jwtMiddleware.Handle(limit("send_coin")(idempotency.Handle(coinHandler.SendCoinBatch)))).Methods(http.MethodPost)
+	r.HandleFunc("/history/coins", jwtMiddleware.Handle(limit("history")(coinHandler.GetHistory))).Methods(http.MethodGet)
+	r.HandleFunc("/buy/{item}", jwtMiddleware.Handle(limit("buy")(idempotency.Handle(shopHandler.BuyMerch)))).Methods(http.MethodGet)
+	r.HandleFunc("/orders", jwtMiddleware.Handle(limit("orders")(idempotency.Handle(shopHandler.CreateOrder)))).Methods(http.MethodPost)
+	r.HandleFunc("/scheduled-transfers", jwtMiddleware.Handle(limit("history")(scheduledHandler.List))).Methods(http.MethodGet)
+	r.HandleFunc("/scheduled-transfers", jwtMiddleware.Handle(limit("scheduled")(scheduledHandler.Create))).Methods(http.MethodPost)
+	r.HandleFunc("/scheduled-transfers/{id}", jwtMiddleware.Handle(limit("scheduled")(scheduledHandler.Update))).Methods(http.MethodPut)
+	r.HandleFunc("/scheduled-transfers/{id}", jwtMiddleware.Handle(limit("scheduled")(scheduledHandler.Cancel))).Methods(http.MethodDelete)
+	r.HandleFunc("/coin-requests", jwtMiddleware.Handle(limit("history")(coinRequestHandler.List))).Methods(http.MethodGet)
+	r.HandleFunc("/coin-requests", jwtMiddleware.Handle(limit("coin_request")(coinRequestHandler.Create))).Methods(http.MethodPost)
+	r.HandleFunc("/coin-requests/{id}/accept", jwtMiddleware.Handle(limit("send_coin")(idempotency.Handle(coinRequestHandler.Accept)))).Methods(http.MethodPost)
+	r.HandleFunc("/coin-requests/{id}/decline", jwtMiddleware.Handle(limit("coin_request")(coinRequestHandler.Decline))).Methods(http.MethodPost)
+	r.HandleFunc("/pending-transfers", jwtMiddleware.Handle(limit("history")(escrowHandler.List))).Methods(http.MethodGet)
+	r.HandleFunc("/pending-transfers/{id}/accept", jwtMiddleware.Handle(limit("escrow")(escrowHandler.Accept))).Methods(http.MethodPost)
+	r.HandleFunc("/pending-transfers/{id}/reject", jwtMiddleware.Handle(limit("escrow")(escrowHandler.Reject))).Methods(http.MethodPost)
+	r.HandleFunc("/inventory", jwtMiddleware.Handle(limit("history")(refundHandler.List))).Methods(http.MethodGet)
+	r.HandleFunc("/inventory/{id}/return", jwtMiddleware.Handle(limit("return")(idempotency.Handle(refundHandler.Return)))).Methods(http.MethodPost)
+	r.HandleFunc("/auth", limit("auth")(authHandler.Auth)).Methods(http.MethodPost)
+	r.HandleFunc("/auth/refresh", limit("auth")(authHandler.Refresh)).Methods(http.MethodPost)
+	r.HandleFunc("/logout", jwtMiddleware.Handle(limit("auth")(authHandler.Logout))).Methods(http.MethodPost)
+	r.HandleFunc("/register", limit("register")(authHandler.Register)).Methods(http.MethodPost)
+	r.HandleFunc("/me/password", jwtMiddleware.Handle(limit("password")(passwordHandler.Change))).Methods(http.MethodPost)
+	r.HandleFunc("/password/reset", limit("password")(passwordHandler.Reset)).Methods(http.MethodPost)
+	r.HandleFunc("/merch", limit("merch")(shopHandler.GetCatalog)).Methods(http.MethodGet)
+
+	requireAdmin := delivery.RequireRole(entity.RoleAdmin)
+	adminOnly := func(next http.HandlerFunc) http.HandlerFunc {
+		return jwtMiddleware.Handle(requireAdmin(limit("admin")(next)))
+	}
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.HandleFunc("/merch", adminOnly(merchAdminHandler.List)).Methods(http.MethodGet)
+	admin.HandleFunc("/merch", adminOnly(merchAdminHandler.Create)).Methods(http.MethodPost)
+	admin.HandleFunc("/merch/{id}", adminOnly(merchAdminHandler.Update)).Methods(http.MethodPut)
+	admin.HandleFunc("/merch/{id}", adminOnly(merchAdminHandler.Archive)).Methods(http.MethodDelete)
+	admin.HandleFunc("/users/{id}/password-reset", adminOnly(passwordHandler.IssueReset)).Methods(http.MethodPost)
+	admin.HandleFunc("/users/{id}/inventory", adminOnly(refundHandler.AdminList)).Methods(http.MethodGet)
+	admin.HandleFunc("/users/{id}/inventory/{item}/return", adminOnly(idempotency.Handle(refundHandler.AdminReturn))).Methods(http.MethodPost)
+	admin.HandleFunc("/allowance/preview", adminOnly(allowanceHandler.Preview)).Methods(http.MethodGet)
+	admin.HandleFunc("/allowance/grants", adminOnly(allowanceHandler.ListGrants)).Methods(http.MethodGet)
 
 	srv := &http.Server{
 		Addr:              fmt.Sprintf(":%s", os.Getenv("SERVER_PORT")),
-		Handler:           r,
+		Handler:           root,
 		ReadTimeout:       cfg.Server.ReadTimeout,
 		WriteTimeout:      cfg.Server.WriteTimeout,
 		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
 		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers sync.WaitGroup
+	runWorker := func(name string, every time.Duration, job worker.Job) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker.Run(workerCtx, name, every, logger, job)
+		}()
+	}
+	runWorker("scheduled_transfers", cfg.Scheduled.PollInterval, scheduledUsecase.RunDue)
+	runWorker("escrow_sweeper", cfg.Escrow.SweepInterval, escrowUsecase.ReturnExpired)
+	runWorker("allowance", cfg.Allowance.PollInterval, allowanceUsecase.Run)
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil {
 			log.Fatalf("listen: %s\\n", err)
@@ -91,12 +225,24 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutdown Server ...")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server Shutdown err:", err)
 	}
+	// начатые порции фоновых задач дорабатывают до конца, пока открыт пул соединений
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		log.Println("background jobs did not finish before shutdown timeout")
+	}
 	// catching ctx.Done(). timeout of 5 seconds.
 	select {
 	case <-ctx.Done():
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"avito-winter-2025/internal/usecase"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// Сверка балансов пользователей с историей переводов и покупок.
+// По умолчанию только печатает отчет; с флагом -fix исправляет расхождения.
+//
+//	go run ./cmd/reconcile -format csv
+//	go run ./cmd/reconcile -fix
+func main() {
+	format := flag.String("format", "json", "формат отчета: json или csv")
+	fix := flag.Bool("fix", false, "записать корректирующие проводки (по умолчанию только отчет)")
+	flag.Parse()
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	PG_CONN := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", os.Getenv("DATABASE_USER"), os.Getenv("DATABASE_PASSWORD"), os.Getenv("DATABASE_HOST"), os.Getenv("DATABASE_PORT"), os.Getenv("DATABASE_NAME"))
+	db, err := pgxpool.New(context.Background(), PG_CONN)
+	if err != nil {
+		log.Fatal("Failed to connect to PostgreSQL: ", err)
+	}
+	defer db.Close()
+
+	reconcile := usecase.NewReconcile(repo.NewReconcile(db))
+	report, err := reconcile.Run(context.Background(), *fix)
+	if err != nil {
+		log.Fatal("Reconciliation failed: ", err)
+	}
+
+	if *format == "csv" {
+		err = writeCSV(os.Stdout, report)
+	} else {
+		err = writeJSON(os.Stdout, report)
+	}
+	if err != nil {
+		log.Fatal("Failed to write report: ", err)
+	}
+	for _, m := range report.Mismatches {
+		if !m.Fixed {
+			// ненулевой код позволяет использовать команду в cron и CI
+			os.Exit(2)
+		}
+	}
+}
+
+func writeJSON(w io.Writer, report entity.ReconcileReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeCSV(w io.Writer, report entity.ReconcileReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"user_id", "name", "actual", "expected", "ledger", "diff", "fixed"}); err != nil {
+		return err
+	}
+	for _, m := range report.Mismatches {
+		record := []string{
+			strconv.FormatUint(uint64(m.UserID), 10),
+			m.Name,
+			strconv.FormatInt(m.Actual, 10),
+			strconv.FormatInt(m.Expected, 10),
+			strconv.FormatInt(m.Ledger, 10),
+			strconv.FormatInt(m.Diff, 10),
+			strconv.FormatBool(m.Fixed),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
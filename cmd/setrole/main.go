@@ -0,0 +1,51 @@
+package main
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// Назначение роли пользователю. Через API роль выдать нельзя, поэтому первого
+// администратора назначает оператор:
+//
+//	go run ./cmd/setrole -user alice -role admin
+func main() {
+	name := flag.String("user", "", "имя пользователя")
+	role := flag.String("role", entity.RoleAdmin, "роль: employee, support или admin")
+	flag.Parse()
+	if *name == "" {
+		log.Fatal("user is required")
+	}
+	if !entity.ValidRole(*role) {
+		log.Fatalf("unknown role %q", *role)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	PG_CONN := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", os.Getenv("DATABASE_USER"), os.Getenv("DATABASE_PASSWORD"), os.Getenv("DATABASE_HOST"), os.Getenv("DATABASE_PORT"), os.Getenv("DATABASE_NAME"))
+	db, err := pgxpool.New(context.Background(), PG_CONN)
+	if err != nil {
+		log.Fatal("Failed to connect to PostgreSQL: ", err)
+	}
+	defer db.Close()
+
+	user, err := repo.NewUser(db).SetRole(context.Background(), *name, *role)
+	if err != nil {
+		if errors.Is(err, myErrors.NoUserErr) {
+			log.Fatalf("user %q not found", *name)
+		}
+		log.Fatal("Failed to set role: ", err)
+	}
+	fmt.Printf("user %s (id %d) is now %s\n", user.Name, user.ID, user.Role)
+}
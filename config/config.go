@@ -10,7 +10,15 @@ import (
 )
 
 type Config struct {
-	Server ServerConfig `yaml:"server"`
+	Server      ServerConfig      `yaml:"server"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Refund      RefundConfig      `yaml:"refund"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Scheduled   ScheduledConfig   `yaml:"scheduled_transfers"`
+	CoinRequest CoinRequestConfig `yaml:"coin_requests"`
+	Escrow      EscrowConfig      `yaml:"escrow"`
+	Allowance   AllowanceConfig   `yaml:"allowance"`
 }
 
 type ServerConfig struct {
@@ -23,6 +31,97 @@ type ServerConfig struct {
 	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
 }
 
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type RefundConfig struct {
+	// Window - сколько времени после покупки товар можно вернуть
+	Window time.Duration `yaml:"window"`
+}
+
+type ScheduledConfig struct {
+	// PollInterval - как часто обработчик проверяет наступившие переводы; 0 отключает обработчик на этом экземпляре
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+}
+
+type CoinRequestConfig struct {
+	// TTL - через сколько неоплаченный запрос монет истекает
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type EscrowConfig struct {
+	// Window - сколько времени получатель может принять перевод, прежде чем монеты вернутся отправителю
+	Window time.Duration `yaml:"window"`
+	// SweepInterval - как часто возвращаются просроченные переводы; 0 отключает обработчик на этом экземпляре
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+	BatchSize     int           `yaml:"batch_size"`
+}
+
+type AllowanceConfig struct {
+	// PollInterval - как часто проверяются начисления и сгорания; 0 отключает обработчик на этом экземпляре
+	PollInterval time.Duration           `yaml:"poll_interval"`
+	BatchSize    int                     `yaml:"batch_size"`
+	Policies     []AllowancePolicyConfig `yaml:"policies"`
+}
+
+// AllowancePolicyConfig - политика начисления: amount монет в каждый период длиной period_months
+// (1 - ежемесячно, 3 - ежеквартально); неизрасходованное сгорает через expire_after_months
+type AllowancePolicyConfig struct {
+	Name              string `yaml:"name"`
+	Amount            uint32 `yaml:"amount"`
+	PeriodMonths      int    `yaml:"period_months"`
+	ExpireAfterMonths int    `yaml:"expire_after_months"`
+	Role              string `yaml:"role"`
+}
+
+// RateLimitConfig - лимиты запросов по имени маршрута
+type RateLimitConfig map[string]RateLimitRule
+
+type RateLimitRule struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+type AuthConfig struct {
+	// AutoRegister создает пользователя при первом входе в /api/auth
+	AutoRegister bool `yaml:"auto_register"`
+	// RefreshTTL - срок жизни refresh-токена; срок access-токена задает JWT_DURATION
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+	// RevocationSync - как часто кэш отозванных токенов обновляется из базы
+	RevocationSync time.Duration `yaml:"revocation_sync"`
+	// PasswordResetTTL - срок действия токена сброса пароля, выданного администратором
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl"`
+	Lockout          LockoutConfig `yaml:"lockout"`
+	Signing          SigningConfig `yaml:"signing"`
+}
+
+type SigningConfig struct {
+	// AcceptHS256 - принимать токены без kid, подписанные JWT_SECRET, пока они не истекут
+	AcceptHS256 bool               `yaml:"accept_hs256"`
+	Keys        []SigningKeyConfig `yaml:"keys"`
+}
+
+// SigningKeyConfig - ключ из расписания ротации. Ключ подписывает токены с active_from
+// до появления следующего ключа; retire_at должен наступать не раньше, чем истекут выпущенные им токены.
+type SigningKeyConfig struct {
+	ID         string    `yaml:"kid"`
+	File       string    `yaml:"file"`
+	ActiveFrom time.Time `yaml:"active_from"`
+	RetireAt   time.Time `yaml:"retire_at"`
+}
+
+type LockoutConfig struct {
+	MaxFailures   int           `yaml:"max_failures"`
+	MaxIPFailures int           `yaml:"max_ip_failures"`
+	Window        time.Duration `yaml:"window"`
+	LockDuration  time.Duration `yaml:"lock_duration"`
+	BaseDelay     time.Duration `yaml:"base_delay"`
+	MaxDelay      time.Duration `yaml:"max_delay"`
+}
+
 func Load() *Config {
 	config := &Config{}
 	buf, err := os.ReadFile("config/config.yaml")
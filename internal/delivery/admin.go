@@ -0,0 +1,112 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/request"
+	"avito-winter-2025/internal/utils/response"
+	"errors"
+	"strconv"
+
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type MerchAdminHandler struct {
+	usecase usecase.MerchAdminInterface
+}
+
+func NewMerchAdminHandler(u usecase.MerchAdminInterface) *MerchAdminHandler {
+	return &MerchAdminHandler{usecase: u}
+}
+
+func (h *MerchAdminHandler) List(w http.ResponseWriter, r *http.Request) {
+	res, err := h.usecase.List(context.Background())
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *MerchAdminHandler) Create(w http.ResponseWriter, r *http.Request) {
+	payload := entity.MerchRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	if !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.Create(context.Background(), payload)
+	if err != nil {
+		if errors.Is(err, myErrors.NotUnique) {
+			response.WithError(w, 409, myErrors.NotUnique)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 201)
+}
+
+func (h *MerchAdminHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := merchIDFromVars(r)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	payload := entity.MerchRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	if !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.Update(context.Background(), id, payload)
+	if err != nil {
+		if errors.Is(err, myErrors.NoMerchErr) {
+			response.WithError(w, 404, myErrors.NoMerchErr)
+			return
+		}
+		if errors.Is(err, myErrors.NotUnique) {
+			response.WithError(w, 409, myErrors.NotUnique)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *MerchAdminHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	id, err := merchIDFromVars(r)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	res, err := h.usecase.Archive(context.Background(), id)
+	if err != nil {
+		if errors.Is(err, myErrors.NoMerchErr) {
+			response.WithError(w, 404, myErrors.NoMerchErr)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func merchIDFromVars(r *http.Request) (uint32, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(id), nil
+}
@@ -0,0 +1,52 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	"avito-winter-2025/internal/utils/response"
+	"strconv"
+
+	"context"
+	"net/http"
+)
+
+type AllowanceHandler struct {
+	usecase usecase.AllowanceInterface
+}
+
+func NewAllowanceHandler(u usecase.AllowanceInterface) *AllowanceHandler {
+	return &AllowanceHandler{usecase: u}
+}
+
+// Preview - пробный прогон: кому будут начислены монеты при следующем запуске
+func (h *AllowanceHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	res, err := h.usecase.Preview(context.Background())
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *AllowanceHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := entity.AllowanceGrantFilter{Policy: q.Get("policy"), User: q.Get("user")}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			response.WithError(w, 400, ErrDefault400)
+			return
+		}
+		filter.Limit = limit
+	}
+	if !filter.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.ListGrants(context.Background(), filter)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
@@ -6,8 +6,9 @@ import (
 	myErrors "avito-winter-2025/internal/utils/errors"
 	"avito-winter-2025/internal/utils/request"
 	"avito-winter-2025/internal/utils/response"
-	"avito-winter-2025/internal/utils/token"
 	"errors"
+	"math"
+	"strconv"
 
 	"context"
 	"net/http"
@@ -17,12 +18,12 @@ import (
 
 type AuthHandler struct {
 	usecase usecase.UserInterface
+	tokens  usecase.TokenInterface
 	logger  *zap.Logger
-	jwt     token.JWT
 }
 
-func NewAuthHandler(u usecase.UserInterface, l *zap.Logger, t token.JWT) *AuthHandler {
-	return &AuthHandler{usecase: u, logger: l, jwt: t}
+func NewAuthHandler(u usecase.UserInterface, t usecase.TokenInterface, l *zap.Logger) *AuthHandler {
+	return &AuthHandler{usecase: u, tokens: t, logger: l}
 }
 
 func (h *AuthHandler) Auth(w http.ResponseWriter, r *http.Request) {
@@ -38,9 +39,22 @@ func (h *AuthHandler) Auth(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, 400, ErrDefault400)
 		return
 	}
-	userData, err := h.usecase.Auth(context.Background(), payload)
+	userData, err := h.usecase.Auth(context.Background(), payload, clientIP(r))
 	if err != nil {
 		h.logger.Error(err.Error())
+		var retryErr *myErrors.RetryAfterError
+		if errors.As(err, &retryErr) {
+			seconds := int(math.Ceil(retryErr.RetryAfter.Seconds()))
+			w.Header().Set(retryAfterHeader, strconv.Itoa(max(seconds, 1)))
+		}
+		if errors.Is(err, myErrors.AccountLockedErr) {
+			response.WithError(w, 423, myErrors.AccountLockedErr)
+			return
+		}
+		if errors.Is(err, myErrors.TooManyAttemptsErr) {
+			response.WithError(w, 429, myErrors.TooManyAttemptsErr)
+			return
+		}
 		if errors.Is(err, myErrors.WrongLoginOrPasswordErr) {
 			response.WithError(w, 500, myErrors.WrongLoginOrPasswordErr)
 			return
@@ -48,13 +62,95 @@ func (h *AuthHandler) Auth(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, 500, ErrDefault500)
 		return
 	}
-	jwtToken, err := h.jwt.GenerateToken(userData.ID, userData.Name)
+	h.writeTokens(w, userData, 200)
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	payload := entity.AuthRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil {
+		h.logger.Error(err.Error())
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	if !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	userData, err := h.usecase.Register(context.Background(), payload)
 	if err != nil {
+		if errors.Is(err, myErrors.WeakPasswordErr) {
+			response.WithError(w, 400, myErrors.WeakPasswordErr)
+			return
+		}
+		if errors.Is(err, myErrors.NotUnique) {
+			response.WithError(w, 409, myErrors.NotUnique)
+			return
+		}
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	h.writeTokens(w, userData, 201)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	payload := entity.RefreshRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	pair, err := h.tokens.Refresh(context.Background(), payload.RefreshToken)
+	if err != nil {
+		if errors.Is(err, myErrors.InvalidRefreshTokenErr) {
+			response.WithError(w, 401, myErrors.InvalidRefreshTokenErr)
+			return
+		}
 		h.logger.Error(err.Error())
 		response.WithError(w, 500, ErrTokenGenerate)
 		return
 	}
-	w.Header().Set("Authorization", "Bearer "+jwtToken)
-	res := entity.AuthResponse{Token: jwtToken}
+	w.Header().Set("Authorization", "Bearer "+pair.AccessToken)
+	res := entity.AuthResponse{Token: pair.AccessToken, RefreshToken: pair.RefreshToken}
 	response.WriteData(w, res, 200)
 }
+
+// Logout отзывает текущий access-токен. Тело с refresh-токеном необязательно:
+// если оно передано, отзывается и refresh-токен.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	access, ok := r.Context().Value(tokenKey).(entity.AccessToken)
+	if !ok || access.ID == "" {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	payload := entity.RefreshRequest{}
+	if r.ContentLength != 0 {
+		if err := request.GetRequestData(r, &payload); err != nil {
+			response.WithError(w, 400, ErrDefault400)
+			return
+		}
+	}
+	err := h.tokens.Logout(context.Background(), user.ID, access, payload.RefreshToken)
+	if err != nil {
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, nil, 200)
+}
+
+func (h *AuthHandler) writeTokens(w http.ResponseWriter, user entity.User, status int) {
+	pair, err := h.tokens.Issue(context.Background(), user)
+	if err != nil {
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrTokenGenerate)
+		return
+	}
+	w.Header().Set("Authorization", "Bearer "+pair.AccessToken)
+	res := entity.AuthResponse{Token: pair.AccessToken, RefreshToken: pair.RefreshToken}
+	response.WriteData(w, res, status)
+}
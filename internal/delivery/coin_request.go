@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/request"
+	"avito-winter-2025/internal/utils/response"
+	"errors"
+	"strconv"
+
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type CoinRequestHandler struct {
+	usecase usecase.CoinRequestInterface
+}
+
+func NewCoinRequestHandler(u usecase.CoinRequestInterface) *CoinRequestHandler {
+	return &CoinRequestHandler{usecase: u}
+}
+
+func (h *CoinRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	payload := entity.CreateCoinRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.Create(context.Background(), user.ID, payload)
+	if err != nil {
+		writeCoinRequestError(w, err)
+		return
+	}
+	response.WriteData(w, res, 201)
+}
+
+func (h *CoinRequestHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	direction := r.URL.Query().Get("direction")
+	if direction != "" && direction != entity.CoinRequestsIncoming && direction != entity.CoinRequestsOutgoing {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.List(context.Background(), user.ID, direction)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *CoinRequestHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.usecase.Accept)
+}
+
+func (h *CoinRequestHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.usecase.Decline)
+}
+
+func (h *CoinRequestHandler) resolve(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userId uint32, id uint32) (entity.CoinRequest, error)) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	res, err := action(context.Background(), user.ID, uint32(id))
+	if err != nil {
+		writeCoinRequestError(w, err)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func writeCoinRequestError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, myErrors.NoUserErr):
+		response.WithError(w, 400, myErrors.NoUserErr)
+	case errors.Is(err, myErrors.InvalidCoinRequestErr):
+		response.WithError(w, 400, myErrors.InvalidCoinRequestErr)
+	case errors.Is(err, myErrors.NotEnoughCoinErr):
+		response.WithError(w, 400, myErrors.NotEnoughCoinErr)
+	case errors.Is(err, myErrors.NoCoinRequestErr):
+		response.WithError(w, 404, myErrors.NoCoinRequestErr)
+	default:
+		response.WithError(w, 500, ErrDefault500)
+	}
+}
@@ -10,15 +10,20 @@ import (
 
 	"context"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type CoinHandler struct {
-	coinUC usecase.CoinInterface
-	userUC usecase.UserInterface
+	coinUC   usecase.CoinInterface
+	userUC   usecase.UserInterface
+	escrowUC usecase.EscrowInterface
 }
 
-func NewCoinHandler(c usecase.CoinInterface, u usecase.UserInterface) *CoinHandler {
-	return &CoinHandler{coinUC: c, userUC: u}
+func NewCoinHandler(c usecase.CoinInterface, u usecase.UserInterface, e usecase.EscrowInterface) *CoinHandler {
+	return &CoinHandler{coinUC: c, userUC: u, escrowUC: e}
 }
 
 func (h *CoinHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
@@ -45,7 +50,11 @@ func (h *CoinHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, 500, ErrDefault500)
 		return
 	}
-	err = h.coinUC.SendCoin(context.Background(), from.ID, to.ID, uint32(payload.Amount))
+	if payload.Escrow {
+		h.holdCoin(w, from.ID, to.ID, payload)
+		return
+	}
+	err = h.coinUC.SendCoin(context.Background(), from.ID, to.ID, uint32(payload.Amount), payload.Note())
 	if err != nil {
 		if errors.Is(err, myErrors.NotEnoughCoinErr) {
 			response.WithError(w, 400, myErrors.NotEnoughCoinErr)
@@ -56,3 +65,112 @@ func (h *CoinHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
 	}
 	response.WriteData(w, nil, 200)
 }
+
+// holdCoin удерживает монеты до решения получателя вместо немедленного перевода
+func (h *CoinHandler) holdCoin(w http.ResponseWriter, from uint32, to uint32, payload entity.SendCoinRequest) {
+	// такой перевод некому подтвердить, кроме самого отправителя
+	if from == to {
+		response.WithError(w, 400, myErrors.SelfEscrowErr)
+		return
+	}
+	res, err := h.escrowUC.Hold(context.Background(), from, to, uint32(payload.Amount), payload.Note())
+	if err != nil {
+		if errors.Is(err, myErrors.NotEnoughCoinErr) {
+			response.WithError(w, 400, myErrors.NotEnoughCoinErr)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 201)
+}
+
+func (h *CoinHandler) SendCoinBatch(w http.ResponseWriter, r *http.Request) {
+	from, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	payload := entity.BatchSendCoinRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.coinUC.SendCoinBatch(context.Background(), from.ID, payload)
+	if err != nil {
+		var unknownErr *myErrors.UnknownUsersError
+		if errors.As(err, &unknownErr) {
+			response.WriteData(w, entity.UnknownUsersResponse{Error: unknownErr.Error(), UnknownUsers: unknownErr.Names}, 400)
+			return
+		}
+		if errors.Is(err, myErrors.NotEnoughCoinErr) {
+			response.WithError(w, 400, myErrors.NotEnoughCoinErr)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *CoinHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	filter, err := historyFilterFromQuery(r.URL.Query())
+	if err != nil {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	filter.UserID = user.ID
+	if !filter.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.coinUC.GetCoinHistoryPage(context.Background(), filter)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func historyFilterFromQuery(q url.Values) (entity.CoinHistoryFilter, error) {
+	filter := entity.CoinHistoryFilter{
+		Direction:    q.Get("direction"),
+		Counterparty: q.Get("counterparty"),
+		Category:     strings.ToLower(q.Get("category")),
+		Search:       entity.SanitizeMessage(q.Get("q")),
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = uint32(cursor)
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = &from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = &to
+	}
+	return filter, nil
+}
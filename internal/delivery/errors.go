@@ -5,6 +5,8 @@ import "errors"
 var (
 	ErrDefault400 = errors.New("Неверный запрос")
 	ErrDefault401 = errors.New("Неавторизован")
+	ErrDefault403 = errors.New("Доступ запрещен")
+	ErrDefault429 = errors.New("Слишком много запросов")
 	ErrDefault500 = errors.New("Ошибка сервера")
 
 	ErrTokenGenerate = errors.New("Ошибка генерации токена")
@@ -0,0 +1,74 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/response"
+	"errors"
+	"strconv"
+
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type EscrowHandler struct {
+	usecase usecase.EscrowInterface
+}
+
+func NewEscrowHandler(u usecase.EscrowInterface) *EscrowHandler {
+	return &EscrowHandler{usecase: u}
+}
+
+func (h *EscrowHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	direction := r.URL.Query().Get("direction")
+	if direction != "" && direction != entity.DirectionSent && direction != entity.DirectionReceived {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.List(context.Background(), user.ID, direction)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *EscrowHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.usecase.Accept)
+}
+
+func (h *EscrowHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.usecase.Reject)
+}
+
+func (h *EscrowHandler) resolve(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userId uint32, id uint32) (entity.PendingTransfer, error)) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	res, err := action(context.Background(), user.ID, uint32(id))
+	if err != nil {
+		if errors.Is(err, myErrors.NoPendingTransferErr) {
+			response.WithError(w, 404, myErrors.NoPendingTransferErr)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
@@ -0,0 +1,134 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/response"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	replayedHeader    = "Idempotent-Replayed"
+	maxIdempotencyKey = 255
+)
+
+type IdempotencyMiddleware struct {
+	usecase usecase.IdempotencyInterface
+	logger  *zap.Logger
+}
+
+func NewIdempotencyMiddleware(u usecase.IdempotencyInterface, l *zap.Logger) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{usecase: u, logger: l}
+}
+
+// responseRecorder пропускает ответ клиенту и параллельно запоминает его
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Handle должен вызываться внутри JWTMiddleware: ключ привязан к пользователю.
+// Запрос без заголовка Idempotency-Key выполняется как обычно.
+func (m *IdempotencyMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			response.WithError(w, 400, ErrDefault400)
+			return
+		}
+		user, ok := r.Context().Value(userKey).(entity.User)
+		if !ok {
+			response.WithError(w, 401, ErrDefault401)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			response.WithError(w, 400, ErrDefault400)
+			return
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		saved, err := m.usecase.Start(context.Background(), key, user.ID, requestHash)
+		if err != nil {
+			if errors.Is(err, myErrors.IdempotencyKeyReusedErr) {
+				response.WithError(w, 422, myErrors.IdempotencyKeyReusedErr)
+				return
+			}
+			if errors.Is(err, myErrors.IdempotencyInProgressErr) {
+				response.WithError(w, 409, myErrors.IdempotencyInProgressErr)
+				return
+			}
+			response.WithError(w, 500, ErrDefault500)
+			return
+		}
+		if saved != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(replayedHeader, "true")
+			w.WriteHeader(saved.StatusCode)
+			w.Write(saved.Response)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		// ошибки сервера не запоминаем, чтобы клиент мог повторить запрос
+		if rec.status >= 500 {
+			if err := m.usecase.Cancel(context.Background(), key, user.ID); err != nil {
+				// ключ останется в обработке до истечения TTL, повтор получит 409
+				m.logger.Error("Failed to cancel idempotency key",
+					zap.Uint32("user", user.ID), zap.String("error", err.Error()))
+			}
+			return
+		}
+		err = m.usecase.Finish(context.Background(), entity.IdempotencyRecord{
+			Key:         key,
+			UserID:      user.ID,
+			RequestHash: requestHash,
+			StatusCode:  rec.status,
+			Response:    rec.body.Bytes(),
+		})
+		if err != nil {
+			// операция уже выполнена, а ключ останется в обработке: повтор получит 409 до истечения TTL
+			m.logger.Error("Failed to save idempotent response",
+				zap.Uint32("user", user.ID), zap.Int("status", rec.status), zap.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/utils/response"
+	"avito-winter-2025/internal/utils/token"
+	"net/http"
+)
+
+type JWKSHandler struct {
+	keys *token.KeySet
+}
+
+func NewJWKSHandler(keys *token.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// Get отдает публичные ключи для проверки access-токенов другими сервисами
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	response.WriteData(w, h.keys.JWKS(), 200)
+}
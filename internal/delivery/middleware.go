@@ -2,21 +2,31 @@ package delivery
 
 import (
 	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
 	"avito-winter-2025/internal/utils/response"
 	"avito-winter-2025/internal/utils/token"
 	"context"
 	"fmt"
 	"net/http"
-	"os"
+	"slices"
 	"strings"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 var userKey string = "user"
 
-func JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// tokenKey - ключ контекста с jti и сроком текущего access-токена
+var tokenKey string = "token"
+
+type JWTMiddleware struct {
+	jwt    token.JWT
+	tokens usecase.TokenInterface
+}
+
+func NewJWTMiddleware(j token.JWT, t usecase.TokenInterface) *JWTMiddleware {
+	return &JWTMiddleware{jwt: j, tokens: t}
+}
+
+func (m *JWTMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -32,30 +42,52 @@ func JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		}
 		tokenString := parts[1]
 
-		claims := &token.Claims{}
-		t, err := jwt.ParseWithClaims(tokenString, claims, func(tok *jwt.Token) (interface{}, error) {
-			if _, ok := tok.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("недопустимый метод подписи")
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		claims, err := m.jwt.ParseToken(tokenString)
 		if err != nil {
 			response.WithError(w, 401, ErrDefault401)
 			return
 		}
-		if !t.Valid {
-			fmt.Println("Недействительный токен")
-			response.WithError(w, 401, ErrDefault401)
+		access := entity.AccessToken{ID: claims.ID, UserID: claims.UserID, ExpiresAt: claims.ExpiresAt.Time}
+		if claims.IssuedAt != nil {
+			access.IssuedAt = claims.IssuedAt.Time
 		}
-
-		if claims.ExpiresAt.Time.Before(time.Now()) {
+		revoked, err := m.tokens.IsRevoked(r.Context(), access)
+		if err != nil {
+			response.WithError(w, 500, ErrDefault500)
+			return
+		}
+		if revoked {
 			response.WithError(w, 401, ErrDefault401)
 			return
 		}
 
-		user := entity.User{ID: claims.UserID, Name: claims.Name}
+		role := claims.Role
+		if role == "" {
+			role = entity.RoleEmployee
+		}
+		user := entity.User{ID: claims.UserID, Name: claims.Name, Role: role}
 		ctx := context.WithValue(r.Context(), userKey, user)
+		ctx = context.WithValue(ctx, tokenKey, access)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	}
 }
+
+// RequireRole пропускает только пользователей с одной из указанных ролей.
+// Возвращаемый middleware должен вызываться внутри JWTMiddleware.
+func RequireRole(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(userKey).(entity.User)
+			if !ok {
+				response.WithError(w, 401, ErrDefault401)
+				return
+			}
+			if !slices.Contains(roles, user.Role) {
+				response.WithError(w, 403, ErrDefault403)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
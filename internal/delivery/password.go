@@ -0,0 +1,117 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/request"
+	"avito-winter-2025/internal/utils/response"
+	"errors"
+	"strconv"
+
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type PasswordHandler struct {
+	usecase usecase.PasswordInterface
+	tokens  usecase.TokenInterface
+	logger  *zap.Logger
+}
+
+func NewPasswordHandler(u usecase.PasswordInterface, t usecase.TokenInterface, l *zap.Logger) *PasswordHandler {
+	return &PasswordHandler{usecase: u, tokens: t, logger: l}
+}
+
+// Change меняет пароль текущего пользователя. Старые токены после этого не принимаются,
+// поэтому в ответе возвращается новая пара.
+func (h *PasswordHandler) Change(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	payload := entity.ChangePasswordRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	err := h.usecase.Change(context.Background(), user.ID, payload)
+	if err != nil {
+		if errors.Is(err, myErrors.WeakPasswordErr) {
+			response.WithError(w, 400, myErrors.WeakPasswordErr)
+			return
+		}
+		if errors.Is(err, myErrors.WrongPasswordErr) {
+			response.WithError(w, 403, myErrors.WrongPasswordErr)
+			return
+		}
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	// токены с iat в секунду смены пароля отзываются, поэтому новая пара
+	// выпускается не раньше следующей секунды
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	pair, err := h.tokens.Issue(context.Background(), user)
+	if err != nil {
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrTokenGenerate)
+		return
+	}
+	w.Header().Set("Authorization", "Bearer "+pair.AccessToken)
+	res := entity.AuthResponse{Token: pair.AccessToken, RefreshToken: pair.RefreshToken}
+	response.WriteData(w, res, 200)
+}
+
+// IssueReset выдает администратору одноразовый токен сброса пароля пользователя
+func (h *PasswordHandler) IssueReset(w http.ResponseWriter, r *http.Request) {
+	admin, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	res, err := h.usecase.IssueReset(context.Background(), admin.ID, uint32(id))
+	if err != nil {
+		if errors.Is(err, myErrors.NoUserErr) {
+			response.WithError(w, 404, myErrors.NoUserErr)
+			return
+		}
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 201)
+}
+
+func (h *PasswordHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	payload := entity.ResetPasswordRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	err := h.usecase.Reset(context.Background(), payload)
+	if err != nil {
+		if errors.Is(err, myErrors.WeakPasswordErr) {
+			response.WithError(w, 400, myErrors.WeakPasswordErr)
+			return
+		}
+		if errors.Is(err, myErrors.InvalidResetTokenErr) {
+			response.WithError(w, 400, myErrors.InvalidResetTokenErr)
+			return
+		}
+		h.logger.Error(err.Error())
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, nil, 200)
+}
@@ -0,0 +1,58 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/utils/ratelimit"
+	"avito-winter-2025/internal/utils/response"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+const retryAfterHeader = "Retry-After"
+
+type RateLimitMiddleware struct {
+	limiter ratelimit.Limiter
+}
+
+func NewRateLimitMiddleware(l ratelimit.Limiter) *RateLimitMiddleware {
+	return &RateLimitMiddleware{limiter: l}
+}
+
+// Handle ограничивает частоту запросов к маршруту route. Запросы авторизованных
+// пользователей считаются по id из JWT, поэтому middleware ставится после JWTMiddleware;
+// для остальных запросов ключом служит IP клиента.
+func (m *RateLimitMiddleware) Handle(route string, limit ratelimit.Limit) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if !limit.Enabled() {
+			return next
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			ok, retryAfter := m.limiter.Allow(route+":"+rateLimitKey(r), limit)
+			if !ok {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set(retryAfterHeader, strconv.Itoa(max(seconds, 1)))
+				response.WithError(w, 429, ErrDefault429)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+func rateLimitKey(r *http.Request) string {
+	if user, ok := r.Context().Value(userKey).(entity.User); ok {
+		return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+	}
+	return "ip:" + clientIP(r)
+}
+
+// clientIP возвращает адрес клиента без порта
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
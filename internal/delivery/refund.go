@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/response"
+	"errors"
+	"strconv"
+
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type RefundHandler struct {
+	usecase usecase.RefundInterface
+}
+
+func NewRefundHandler(u usecase.RefundInterface) *RefundHandler {
+	return &RefundHandler{usecase: u}
+}
+
+func (h *RefundHandler) Return(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	h.returnItem(w, user.ID, uint32(id))
+}
+
+// List возвращает покупки пользователя с id, которые можно передать в Return
+func (h *RefundHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	h.listItems(w, user.ID)
+}
+
+// AdminList показывает администратору покупки сотрудника
+func (h *RefundHandler) AdminList(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	h.listItems(w, uint32(userId))
+}
+
+// AdminReturn оформляет возврат покупки от имени сотрудника
+func (h *RefundHandler) AdminReturn(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userId, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	itemId, err := strconv.ParseUint(vars["item"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	h.returnItem(w, uint32(userId), uint32(itemId))
+}
+
+func (h *RefundHandler) listItems(w http.ResponseWriter, userId uint32) {
+	res, err := h.usecase.ListInventory(context.Background(), userId)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *RefundHandler) returnItem(w http.ResponseWriter, userId uint32, itemId uint32) {
+	res, err := h.usecase.Return(context.Background(), userId, itemId)
+	if err != nil {
+		if errors.Is(err, myErrors.NoInventoryErr) {
+			response.WithError(w, 404, myErrors.NoInventoryErr)
+			return
+		}
+		if errors.Is(err, myErrors.AlreadyReturnedErr) {
+			response.WithError(w, 409, myErrors.AlreadyReturnedErr)
+			return
+		}
+		if errors.Is(err, myErrors.ReturnWindowExpiredErr) {
+			response.WithError(w, 400, myErrors.ReturnWindowExpiredErr)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
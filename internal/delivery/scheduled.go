@@ -0,0 +1,113 @@
+package delivery
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/usecase"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/request"
+	"avito-winter-2025/internal/utils/response"
+	"errors"
+	"strconv"
+
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type ScheduledHandler struct {
+	usecase usecase.ScheduledInterface
+}
+
+func NewScheduledHandler(u usecase.ScheduledInterface) *ScheduledHandler {
+	return &ScheduledHandler{usecase: u}
+}
+
+func (h *ScheduledHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	payload := entity.ScheduledTransferRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.Create(context.Background(), user.ID, payload)
+	if err != nil {
+		writeScheduledError(w, err)
+		return
+	}
+	response.WriteData(w, res, 201)
+}
+
+func (h *ScheduledHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	res, err := h.usecase.List(context.Background(), user.ID)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *ScheduledHandler) Update(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	payload := entity.ScheduledTransferRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil || !payload.Valid() {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.usecase.Update(context.Background(), user.ID, uint32(id), payload)
+	if err != nil {
+		writeScheduledError(w, err)
+		return
+	}
+	response.WriteData(w, res, 200)
+}
+
+func (h *ScheduledHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		response.WithError(w, 400, ErrNoRequestVars)
+		return
+	}
+	err = h.usecase.Cancel(context.Background(), user.ID, uint32(id))
+	if err != nil {
+		writeScheduledError(w, err)
+		return
+	}
+	response.WriteData(w, nil, 200)
+}
+
+func writeScheduledError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, myErrors.NoUserErr):
+		response.WithError(w, 400, myErrors.NoUserErr)
+	case errors.Is(err, myErrors.InvalidScheduleErr):
+		response.WithError(w, 400, myErrors.InvalidScheduleErr)
+	case errors.Is(err, myErrors.NoScheduledTransferErr):
+		response.WithError(w, 404, myErrors.NoScheduledTransferErr)
+	default:
+		response.WithError(w, 500, ErrDefault500)
+	}
+}
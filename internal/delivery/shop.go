@@ -4,6 +4,7 @@ import (
 	"avito-winter-2025/internal/entity"
 	"avito-winter-2025/internal/usecase"
 	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/request"
 	"avito-winter-2025/internal/utils/response"
 	"errors"
 
@@ -45,6 +46,10 @@ func (h *ShopHandler) BuyMerch(w http.ResponseWriter, r *http.Request) {
 			response.WithError(w, 400, myErrors.NoMerchErr)
 			return
 		}
+		if errors.Is(err, myErrors.OutOfStockErr) {
+			response.WithError(w, 409, myErrors.OutOfStockErr)
+			return
+		}
 		response.WithError(w, 500, ErrDefault500)
 		return
 	}
@@ -57,7 +62,7 @@ func (h *ShopHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, 401, ErrDefault401)
 		return
 	}
-	u, err := h.userUC.GetUser(context.Background(), "", user.ID)
+	_, err := h.userUC.GetUser(context.Background(), "", user.ID)
 	if err != nil {
 		if errors.Is(err, myErrors.NoUserErr) {
 			response.WithError(w, 400, myErrors.NoUserErr)
@@ -66,6 +71,11 @@ func (h *ShopHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 		response.WithError(w, 500, ErrDefault500)
 		return
 	}
+	balance, err := h.coinUC.GetBalance(context.Background(), user.ID)
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
 	inventory, err := h.merchUC.GetInventoryHistory(context.Background(), user.ID)
 	if err != nil {
 		response.WithError(w, 500, ErrDefault500)
@@ -77,9 +87,53 @@ func (h *ShopHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	res := entity.InfoResponse{
-		Coins:       u.Coins,
+		Coins:       balance,
 		Inventory:   inventory,
 		CoinHistory: coinHistory,
 	}
 	response.WriteData(w, res, 200)
 }
+
+func (h *ShopHandler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	res, err := h.merchUC.GetCatalog(context.Background())
+	if err != nil {
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteDataWithETag(w, r, res)
+}
+
+func (h *ShopHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userKey).(entity.User)
+	if !ok {
+		response.WithError(w, 401, ErrDefault401)
+		return
+	}
+	payload := entity.OrderRequest{}
+	if err := request.GetRequestData(r, &payload); err != nil {
+		response.WithError(w, 400, ErrDefault400)
+		return
+	}
+	res, err := h.merchUC.CreateOrder(context.Background(), user.ID, payload)
+	if err != nil {
+		if errors.Is(err, myErrors.InvalidOrderErr) {
+			response.WithError(w, 400, myErrors.InvalidOrderErr)
+			return
+		}
+		if errors.Is(err, myErrors.NotEnoughCoinErr) {
+			response.WithError(w, 400, myErrors.NotEnoughCoinErr)
+			return
+		}
+		if errors.Is(err, myErrors.NoMerchErr) {
+			response.WithError(w, 400, myErrors.NoMerchErr)
+			return
+		}
+		if errors.Is(err, myErrors.OutOfStockErr) {
+			response.WithError(w, 409, myErrors.OutOfStockErr)
+			return
+		}
+		response.WithError(w, 500, ErrDefault500)
+		return
+	}
+	response.WriteData(w, res, 201)
+}
@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// AllowancePolicy - правило периодического начисления монет
+type AllowancePolicy struct {
+	Name   string
+	Amount uint32
+	// PeriodMonths - длина периода в месяцах; периоды отсчитываются от начала года
+	PeriodMonths int
+	// ExpireAfterMonths - через сколько месяцев неизрасходованная часть начисления сгорает; 0 - не сгорает
+	ExpireAfterMonths int
+	// Role ограничивает начисление пользователями с этой ролью; пустая строка - все пользователи
+	Role string
+}
+
+func (p AllowancePolicy) Valid() bool {
+	return categoryPattern.MatchString(p.Name) && p.Amount > 0 &&
+		p.PeriodMonths > 0 && 12%p.PeriodMonths == 0 && p.ExpireAfterMonths >= 0
+}
+
+// Period возвращает первый день периода, в который попадает now (UTC)
+func (p AllowancePolicy) Period(now time.Time) time.Time {
+	now = now.UTC()
+	month := (int(now.Month()) - 1) / p.PeriodMonths * p.PeriodMonths
+	return time.Date(now.Year(), time.Month(month+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ExpiresAt возвращает время сгорания начисления, сделанного в now, или nil, если монеты не сгорают
+func (p AllowancePolicy) ExpiresAt(now time.Time) *time.Time {
+	if p.ExpireAfterMonths == 0 {
+		return nil
+	}
+	expiresAt := now.AddDate(0, p.ExpireAfterMonths, 0)
+	return &expiresAt
+}
+
+type AllowanceGrant struct {
+	ID            uint32     `json:"id"`
+	Policy        string     `json:"policy"`
+	UserID        uint32     `json:"userId"`
+	User          string     `json:"user"`
+	Period        time.Time  `json:"period"`
+	Amount        uint32     `json:"amount"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	ExpiredAt     *time.Time `json:"expiredAt,omitempty"`
+	ExpiredAmount uint32     `json:"expiredAmount,omitempty"`
+}
+
+type AllowanceGrantFilter struct {
+	Policy string
+	User   string
+	Limit  int
+}
+
+func (f AllowanceGrantFilter) Valid() bool {
+	return f.Limit >= 0 && f.Limit <= MaxHistoryLimit
+}
+
+// AllowanceCredit - начисление, которое будет сделано при следующем запуске
+type AllowanceCredit struct {
+	Policy string    `json:"policy"`
+	Period time.Time `json:"period"`
+	UserID uint32    `json:"userId"`
+	User   string    `json:"user"`
+	Amount uint32    `json:"amount"`
+}
+
+type AllowancePreview struct {
+	DryRun  bool              `json:"dryRun"`
+	Credits []AllowanceCredit `json:"credits"`
+	Total   uint64            `json:"total"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowancePolicy_Period(t *testing.T) {
+	now := time.Date(2025, 8, 17, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		months int
+		want   time.Time
+	}{
+		{name: "Monthly", months: 1, want: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Quarterly", months: 3, want: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Yearly", months: 12, want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, AllowancePolicy{PeriodMonths: tt.months}.Period(now))
+		})
+	}
+}
+
+func TestAllowancePolicy_ExpiresAt(t *testing.T) {
+	now := time.Date(2025, 8, 17, 15, 30, 0, 0, time.UTC)
+	assert.Nil(t, AllowancePolicy{}.ExpiresAt(now))
+	expiresAt := AllowancePolicy{ExpireAfterMonths: 3}.ExpiresAt(now)
+	assert.Equal(t, time.Date(2025, 11, 17, 15, 30, 0, 0, time.UTC), *expiresAt)
+}
+
+func TestAllowancePolicy_Valid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy AllowancePolicy
+		want   bool
+	}{
+		{name: "Valid", policy: AllowancePolicy{Name: "monthly", Amount: 200, PeriodMonths: 1, ExpireAfterMonths: 3}, want: true},
+		{name: "Without amount", policy: AllowancePolicy{Name: "monthly", PeriodMonths: 1}, want: false},
+		{name: "Period does not divide year", policy: AllowancePolicy{Name: "odd", Amount: 200, PeriodMonths: 5}, want: false},
+		{name: "Invalid name", policy: AllowancePolicy{Name: "Ежемесячно", Amount: 200, PeriodMonths: 1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Valid())
+		})
+	}
+}
@@ -1,31 +1,187 @@
 package entity
 
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
 type SendCoinRequest struct {
-	ToUser string `json:"toUser"`
-	Amount int    `json:"amount"`
+	ToUser   string `json:"toUser"`
+	Amount   int    `json:"amount"`
+	Message  string `json:"message,omitempty"`
+	Category string `json:"category,omitempty"`
+	// Escrow удерживает монеты, пока получатель не примет перевод
+	Escrow bool `json:"escrow,omitempty"`
 }
 
 func (s SendCoinRequest) Valid() bool {
-	return s.ToUser != "" && s.Amount > 0
+	return s.ToUser != "" && s.Amount > 0 && s.Note().Valid()
+}
+
+// Note возвращает очищенные сообщение и категорию перевода
+func (s SendCoinRequest) Note() TransferNote {
+	return TransferNote{
+		Message:  SanitizeMessage(s.Message),
+		Category: strings.ToLower(strings.TrimSpace(s.Category)),
+	}
+}
+
+const MaxTransferMessageLength = 200
+
+// MaxBatchTransfers ограничивает число получателей в одном пакетном переводе
+const MaxBatchTransfers = 100
+
+type BatchSendCoinRequest struct {
+	Transfers []SendCoinRequest `json:"transfers"`
+}
+
+func (b BatchSendCoinRequest) Valid() bool {
+	if len(b.Transfers) == 0 || len(b.Transfers) > MaxBatchTransfers {
+		return false
+	}
+	for _, t := range b.Transfers {
+		if !t.Valid() || t.Escrow {
+			return false
+		}
+	}
+	return true
+}
+
+type BatchSendCoinResponse struct {
+	Count int    `json:"count"`
+	Total uint64 `json:"total"`
+}
+
+// UnknownUsersResponse - ответ на пакетный перевод с неизвестными получателями
+type UnknownUsersResponse struct {
+	Error        string   `json:"error"`
+	UnknownUsers []string `json:"unknownUsers"`
+}
+
+var categoryPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// TransferNote - необязательные пояснения к переводу
+type TransferNote struct {
+	Message  string
+	Category string
+}
+
+func (n TransferNote) Valid() bool {
+	if utf8.RuneCountInString(n.Message) > MaxTransferMessageLength {
+		return false
+	}
+	return n.Category == "" || categoryPattern.MatchString(n.Category)
+}
+
+// SanitizeMessage убирает управляющие и невидимые символы форматирования,
+// которыми можно исказить отображение истории, и схлопывает пробелы.
+// Некорректные UTF-8 последовательности отбрасываются.
+func SanitizeMessage(msg string) string {
+	msg = strings.ToValidUTF8(msg, "")
+	msg = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, msg)
+	return strings.Join(strings.Fields(msg), " ")
 }
 
 type Transaction struct {
-	From   uint32
-	To     uint32
-	Amount uint32
+	ID        uint32
+	From      uint32
+	To        uint32
+	FromName  string
+	ToName    string
+	Amount    uint32
+	Message   string
+	Category  string
+	CreatedAt time.Time
 }
 
+// DeletedUserName подставляется вместо имени участника, чья учетная запись удалена
+const DeletedUserName = "deleted"
+
 type CoinHistory struct {
 	Received []Received `json:"received"`
 	Sent     []Sent     `json:"sent"`
+	// Refunds - монеты, возвращенные магазином за сданные покупки
+	Refunds []Refund `json:"refunds"`
 }
 
 type Received struct {
-	FromUser string `json:"fromUser"`
-	Amount   uint32 `json:"amount"`
+	ID        uint32    `json:"id"`
+	FromUser  string    `json:"fromUser"`
+	Amount    uint32    `json:"amount"`
+	Message   string    `json:"message,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type Sent struct {
-	ToUser string `json:"toUser"`
-	Amount uint32 `json:"amount"`
+	ID        uint32    `json:"id"`
+	ToUser    string    `json:"toUser"`
+	Amount    uint32    `json:"amount"`
+	Message   string    `json:"message,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 100
+)
+
+type CoinHistoryFilter struct {
+	UserID       uint32
+	Cursor       uint32
+	Limit        int
+	Direction    string
+	Counterparty string
+	From         *time.Time
+	To           *time.Time
+	Category     string
+	// Search - подстрока для поиска по сообщению перевода без учета регистра
+	Search string
+}
+
+func (f CoinHistoryFilter) Valid() bool {
+	if f.Direction != "" && f.Direction != DirectionSent && f.Direction != DirectionReceived {
+		return false
+	}
+	if f.Limit < 0 || f.Limit > MaxHistoryLimit {
+		return false
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return false
+	}
+	if f.Category != "" && !categoryPattern.MatchString(f.Category) {
+		return false
+	}
+	return utf8.RuneCountInString(f.Search) <= MaxTransferMessageLength
+}
+
+type HistoryEntry struct {
+	ID           uint32    `json:"id"`
+	Direction    string    `json:"direction"`
+	Counterparty string    `json:"counterparty"`
+	Amount       uint32    `json:"amount"`
+	Message      string    `json:"message,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type CoinHistoryPage struct {
+	Entries []HistoryEntry `json:"entries"`
+	// NextCursor передается в параметре cursor для получения следующей страницы
+	NextCursor uint32 `json:"nextCursor,omitempty"`
 }
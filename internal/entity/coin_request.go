@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+const (
+	CoinRequestPending  = "pending"
+	CoinRequestAccepted = "accepted"
+	CoinRequestDeclined = "declined"
+	// CoinRequestExpired не хранится в базе: так отображается pending-запрос после expires_at
+	CoinRequestExpired = "expired"
+
+	// CoinRequestsIncoming - запросы, которые должен оплатить пользователь
+	CoinRequestsIncoming = "incoming"
+	// CoinRequestsOutgoing - запросы, созданные пользователем
+	CoinRequestsOutgoing = "outgoing"
+)
+
+type CreateCoinRequest struct {
+	// FromUser - у кого запрашиваются монеты
+	FromUser string `json:"fromUser"`
+	Amount   int    `json:"amount"`
+	Message  string `json:"message,omitempty"`
+	Category string `json:"category,omitempty"`
+}
+
+func (r CreateCoinRequest) Valid() bool {
+	return SendCoinRequest{ToUser: r.FromUser, Amount: r.Amount, Message: r.Message, Category: r.Category}.Valid()
+}
+
+func (r CreateCoinRequest) Note() TransferNote {
+	return SendCoinRequest{Message: r.Message, Category: r.Category}.Note()
+}
+
+type CoinRequest struct {
+	ID          uint32     `json:"id"`
+	RequesterID uint32     `json:"-"`
+	Requester   string     `json:"requester"`
+	PayerID     uint32     `json:"-"`
+	Payer       string     `json:"payer"`
+	Amount      uint32     `json:"amount"`
+	Message     string     `json:"message,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	ResolvedAt  *time.Time `json:"resolvedAt,omitempty"`
+}
+
+func (r CoinRequest) Note() TransferNote {
+	return TransferNote{Message: r.Message, Category: r.Category}
+}
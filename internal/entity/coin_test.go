@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{name: "Plain", msg: "спасибо за ревью", want: "спасибо за ревью"},
+		{name: "Spaces and newlines", msg: "  спасибо\n\tза   ревью ", want: "спасибо за ревью"},
+		{name: "Control characters", msg: "спа\x00си\x1bбо", want: "спасибо"},
+		{name: "Bidi override", msg: "спасибо‮орбод", want: "спасибоорбод"},
+		{name: "Invalid UTF-8", msg: "спасибо\xff", want: "спасибо"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeMessage(tt.msg))
+		})
+	}
+}
+
+func TestSendCoinRequest_Valid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  SendCoinRequest
+		want bool
+	}{
+		{name: "Without note", req: SendCoinRequest{ToUser: "mary", Amount: 10}, want: true},
+		{name: "With note", req: SendCoinRequest{ToUser: "mary", Amount: 10, Message: "спасибо", Category: " Thanks "}, want: true},
+		{name: "Long message", req: SendCoinRequest{ToUser: "mary", Amount: 10, Message: strings.Repeat("я", MaxTransferMessageLength+1)}, want: false},
+		{name: "Message at limit after trim", req: SendCoinRequest{ToUser: "mary", Amount: 10, Message: " " + strings.Repeat("я", MaxTransferMessageLength) + " "}, want: true},
+		{name: "Invalid category", req: SendCoinRequest{ToUser: "mary", Amount: 10, Category: "спасибо!"}, want: false},
+		{name: "Without amount", req: SendCoinRequest{ToUser: "mary"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.req.Valid())
+		})
+	}
+	assert.Equal(t, TransferNote{Message: "спасибо", Category: "thanks"},
+		SendCoinRequest{Message: " спасибо ", Category: " Thanks "}.Note())
+}
+
+func TestBatchSendCoinRequest_Valid(t *testing.T) {
+	valid := SendCoinRequest{ToUser: "mary", Amount: 10}
+	tooMany := make([]SendCoinRequest, MaxBatchTransfers+1)
+	for i := range tooMany {
+		tooMany[i] = valid
+	}
+	tests := []struct {
+		name string
+		req  BatchSendCoinRequest
+		want bool
+	}{
+		{name: "Valid", req: BatchSendCoinRequest{Transfers: []SendCoinRequest{valid, {ToUser: "john", Amount: 1}}}, want: true},
+		{name: "Empty", req: BatchSendCoinRequest{}, want: false},
+		{name: "Too many", req: BatchSendCoinRequest{Transfers: tooMany}, want: false},
+		{name: "Invalid item", req: BatchSendCoinRequest{Transfers: []SendCoinRequest{valid, {ToUser: "john"}}}, want: false},
+		{name: "Escrow item", req: BatchSendCoinRequest{Transfers: []SendCoinRequest{valid, {ToUser: "john", Amount: 1, Escrow: true}}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.req.Valid())
+		})
+	}
+}
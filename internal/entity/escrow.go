@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+const (
+	EscrowHeld     = "held"
+	EscrowAccepted = "accepted"
+	EscrowRejected = "rejected"
+	// EscrowReturned - перевод не приняли вовремя, монеты вернулись отправителю
+	EscrowReturned = "returned"
+)
+
+// PendingTransfer - перевод, монеты которого удерживаются до решения получателя
+type PendingTransfer struct {
+	ID         uint32     `json:"id"`
+	FromUserID uint32     `json:"-"`
+	FromUser   string     `json:"fromUser"`
+	ToUserID   uint32     `json:"-"`
+	ToUser     string     `json:"toUser"`
+	Amount     uint32     `json:"amount"`
+	Message    string     `json:"message,omitempty"`
+	Category   string     `json:"category,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+type IdempotencyRecord struct {
+	Key         string
+	UserID      uint32
+	RequestHash string
+	// StatusCode равен 0, пока исходный запрос еще обрабатывается
+	StatusCode int
+	Response   []byte
+}
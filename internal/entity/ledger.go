@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// Типы проводок в журнале монет
+const (
+	LedgerInitialGrant = "initial_grant"
+	LedgerTransfer     = "transfer"
+	LedgerPurchase     = "purchase"
+	LedgerRefund       = "refund"
+	LedgerAdjustment   = "adjustment"
+	// перевод с подтверждением: удержание, зачисление получателю и возврат отправителю
+	LedgerEscrowHold    = "escrow_hold"
+	LedgerEscrowRelease = "escrow_release"
+	LedgerEscrowReturn  = "escrow_return"
+	// периодическое начисление по политике и сгорание его неизрасходованной части
+	LedgerAllowance       = "allowance"
+	LedgerAllowanceExpiry = "allowance_expiry"
+)
+
+// Счета журнала. У пользовательского счета задан UserID, системные счета
+// принадлежат сервису: issuance выпускает монеты, shop получает оплату за мерч,
+// escrow хранит монеты переводов, которые еще не принял получатель.
+const (
+	AccountUser     = "user"
+	AccountIssuance = "issuance"
+	AccountShop     = "shop"
+	AccountEscrow   = "escrow"
+)
+
+// LedgerEntry - одна нога проводки. Сумма Amount по всем ногам проводки равна нулю.
+type LedgerEntry struct {
+	Account string
+	UserID  uint32
+	Amount  int64
+}
+
+func UserEntry(userId uint32, amount int64) LedgerEntry {
+	return LedgerEntry{Account: AccountUser, UserID: userId, Amount: amount}
+}
+
+func SystemEntry(account string, amount int64) LedgerEntry {
+	return LedgerEntry{Account: account, Amount: amount}
+}
+
+type LedgerTransaction struct {
+	ID   uint32
+	Kind string
+	// ReferenceID - id связанной записи: перевода, покупки, заказа или возврата
+	ReferenceID *uint32
+	Entries     []LedgerEntry
+	CreatedAt   time.Time
+}
+
+func (t LedgerTransaction) Balanced() bool {
+	if len(t.Entries) < 2 {
+		return false
+	}
+	var sum int64
+	for _, e := range t.Entries {
+		sum += e.Amount
+	}
+	return sum == 0
+}
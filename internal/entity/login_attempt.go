@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+// LoginAttempt - неудачные попытки входа по имени пользователя или по IP клиента
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+func LoginKeyUser(name string) string {
+	return "user:" + name
+}
+
+func LoginKeyIP(ip string) string {
+	return "ip:" + ip
+}
+
+// LockoutPolicy задает задержки между неудачными попытками входа и порог блокировки.
+// Нулевая политика отключает защиту от перебора.
+type LockoutPolicy struct {
+	// MaxFailures - после стольких ошибок подряд учетная запись блокируется
+	MaxFailures int
+	// MaxIPFailures - то же для одного IP, который может перебирать разные имена
+	MaxIPFailures int
+	// Window - ошибки старше окна не учитываются
+	Window       time.Duration
+	LockDuration time.Duration
+	// BaseDelay удваивается с каждой ошибкой, но не превышает MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (p LockoutPolicy) Enabled() bool {
+	return p.MaxFailures > 0 || p.MaxIPFailures > 0
+}
+
+// Delay возвращает, сколько нужно подождать после failures неудачных попыток
+func (p LockoutPolicy) Delay(failures int) time.Duration {
+	if failures <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutPolicy_Delay(t *testing.T) {
+	policy := LockoutPolicy{BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "No failures", failures: 0, want: 0},
+		{name: "First failure", failures: 1, want: time.Second},
+		{name: "Doubles", failures: 3, want: 4 * time.Second},
+		{name: "Capped", failures: 10, want: 30 * time.Second},
+		{name: "Many failures do not overflow", failures: 100, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Delay(tt.failures))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (r ChangePasswordRequest) Valid() bool {
+	return r.OldPassword != "" && r.NewPassword != ""
+}
+
+type ResetPasswordRequest struct {
+	ResetToken  string `json:"resetToken"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (r ResetPasswordRequest) Valid() bool {
+	return r.ResetToken != "" && r.NewPassword != ""
+}
+
+// PasswordReset - токен сброса пароля, который администратор передает пользователю
+type PasswordReset struct {
+	Token     string    `json:"resetToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// PasswordResetToken хранится в базе только в виде хэша
+type PasswordResetToken struct {
+	UserID    uint32
+	TokenHash string
+	CreatedBy uint32
+	ExpiresAt time.Time
+}
@@ -0,0 +1,28 @@
+package entity
+
+// BalanceCheck - баланс пользователя в "user".coins, баланс, пересчитанный по истории,
+// и баланс по журналу проводок
+type BalanceCheck struct {
+	UserID   uint32
+	Name     string
+	Actual   int64
+	Expected int64
+	Ledger   int64
+}
+
+type BalanceMismatch struct {
+	UserID   uint32 `json:"userId"`
+	Name     string `json:"name"`
+	Actual   int64  `json:"actual"`
+	Expected int64  `json:"expected"`
+	Ledger   int64  `json:"ledger"`
+	Diff     int64  `json:"diff"`
+	// Fixed равен true, если баланс исправлен корректирующей проводкой
+	Fixed bool `json:"fixed"`
+}
+
+type ReconcileReport struct {
+	Checked    int               `json:"checked"`
+	DryRun     bool              `json:"dryRun"`
+	Mismatches []BalanceMismatch `json:"mismatches"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Refund struct {
+	ID          uint32    `json:"id"`
+	InventoryID uint32    `json:"inventoryId"`
+	Amount      uint32    `json:"amount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// InventoryItem - отдельная покупка; ее id передается в /api/inventory/{id}/return
+type InventoryItem struct {
+	ID         uint32     `json:"id"`
+	Type       string     `json:"type"`
+	Cost       uint32     `json:"cost"`
+	BoughtAt   time.Time  `json:"boughtAt"`
+	ReturnedAt *time.Time `json:"returnedAt,omitempty"`
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ScheduleActive    = "active"
+	SchedulePaused    = "paused"
+	ScheduleCompleted = "completed"
+	ScheduleFailed    = "failed"
+	ScheduleCancelled = "cancelled"
+
+	// MinScheduleInterval ограничивает частоту повторяющихся переводов
+	MinScheduleInterval = time.Hour
+	// MaxScheduleFailures - после стольких неудачных запусков подряд повторяющийся перевод ставится на паузу
+	MaxScheduleFailures = 3
+)
+
+// Interval - период повторения, в JSON записывается строкой вида "168h"
+type Interval time.Duration
+
+func (i Interval) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(i).String())
+}
+
+func (i *Interval) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*i = Interval(d)
+	return nil
+}
+
+type ScheduledTransferRequest struct {
+	ToUser   string `json:"toUser"`
+	Amount   int    `json:"amount"`
+	Message  string `json:"message,omitempty"`
+	Category string `json:"category,omitempty"`
+	// StartAt - время первого перевода; если не задано, перевод выполняется при ближайшем запуске обработчика
+	StartAt *time.Time `json:"startAt,omitempty"`
+	// Interval не задается для разового перевода
+	Interval Interval `json:"interval,omitempty"`
+	// Paused учитывается только при изменении перевода
+	Paused bool `json:"paused,omitempty"`
+}
+
+func (r ScheduledTransferRequest) Valid() bool {
+	send := SendCoinRequest{ToUser: r.ToUser, Amount: r.Amount, Message: r.Message, Category: r.Category}
+	if !send.Valid() {
+		return false
+	}
+	return r.Interval == 0 || time.Duration(r.Interval) >= MinScheduleInterval
+}
+
+func (r ScheduledTransferRequest) Note() TransferNote {
+	return SendCoinRequest{Message: r.Message, Category: r.Category}.Note()
+}
+
+type ScheduledTransfer struct {
+	ID        uint32     `json:"id"`
+	FromUser  uint32     `json:"-"`
+	ToUserID  uint32     `json:"-"`
+	ToUser    string     `json:"toUser"`
+	Amount    uint32     `json:"amount"`
+	Message   string     `json:"message,omitempty"`
+	Category  string     `json:"category,omitempty"`
+	Interval  Interval   `json:"interval,omitempty"`
+	NextRunAt time.Time  `json:"nextRunAt"`
+	Status    string     `json:"status"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	Failures  int        `json:"failures,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTransferRequest_Valid(t *testing.T) {
+	var req ScheduledTransferRequest
+	err := json.Unmarshal([]byte(`{"toUser":"mary","amount":10,"interval":"168h"}`), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, Interval(168*time.Hour), req.Interval)
+	assert.True(t, req.Valid())
+
+	req.Interval = Interval(time.Minute)
+	assert.False(t, req.Valid())
+	req.Interval = 0
+	assert.True(t, req.Valid())
+
+	err = json.Unmarshal([]byte(`{"toUser":"mary","amount":10,"interval":"weekly"}`), &req)
+	assert.Error(t, err)
+
+	body, err := json.Marshal(ScheduledTransfer{Interval: Interval(24 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"interval":"24h0m0s"`)
+}
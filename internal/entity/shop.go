@@ -1,9 +1,45 @@
 package entity
 
+import "time"
+
 type Merch struct {
-	ID   uint32
-	Name string
-	Cost uint32
+	ID   uint32 `json:"id"`
+	Name string `json:"name"`
+	Cost uint32 `json:"cost"`
+	// Stock равен nil, если количество товара не ограничено
+	Stock      *uint32    `json:"stock"`
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+}
+
+func (m Merch) InStock() bool {
+	return m.Stock == nil || *m.Stock > 0
+}
+
+type CatalogItem struct {
+	ID        uint32  `json:"id"`
+	Name      string  `json:"name"`
+	Cost      uint32  `json:"cost"`
+	Stock     *uint32 `json:"stock"`
+	Available bool    `json:"available"`
+}
+
+type MerchRequest struct {
+	Name  string `json:"name"`
+	Cost  int    `json:"cost"`
+	Stock *int   `json:"stock"`
+}
+
+func (m MerchRequest) Valid() bool {
+	return m.Name != "" && m.Cost > 0 && (m.Stock == nil || *m.Stock >= 0)
+}
+
+func (m MerchRequest) Merch() Merch {
+	merch := Merch{Name: m.Name, Cost: uint32(m.Cost)}
+	if m.Stock != nil {
+		stock := uint32(*m.Stock)
+		merch.Stock = &stock
+	}
+	return merch
 }
 
 type InfoResponse struct {
@@ -16,3 +52,44 @@ type Inventory struct {
 	Type     string `json:"type"`
 	Quantity uint32 `json:"quantity"`
 }
+
+const (
+	MaxOrderLines    = 20
+	MaxOrderQuantity = 100
+)
+
+type OrderLine struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+type OrderRequest struct {
+	Lines []OrderLine `json:"lines"`
+}
+
+func (o OrderRequest) Valid() bool {
+	if len(o.Lines) == 0 || len(o.Lines) > MaxOrderLines {
+		return false
+	}
+	for _, line := range o.Lines {
+		if line.Item == "" || line.Quantity <= 0 || line.Quantity > MaxOrderQuantity {
+			return false
+		}
+	}
+	return true
+}
+
+type OrderItem struct {
+	MerchID  uint32 `json:"-"`
+	Item     string `json:"item"`
+	Quantity uint32 `json:"quantity"`
+	// Cost - цена за единицу на момент покупки
+	Cost uint32 `json:"cost"`
+}
+
+type Order struct {
+	ID        uint32      `json:"id"`
+	Total     uint32      `json:"total"`
+	Lines     []OrderItem `json:"lines"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
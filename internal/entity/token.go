@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// RefreshToken хранится в базе только в виде хэша. Все токены, полученные
+// ротацией из одного входа, образуют семейство Family.
+type RefreshToken struct {
+	ID        uint32
+	UserID    uint32
+	TokenHash string
+	Family    string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// AccessToken - данные текущего access-токена, нужные для его отзыва
+type AccessToken struct {
+	ID        string
+	UserID    uint32
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+type RevokedToken struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// TokenCutoff - время смены пароля пользователя; выпущенные до него access-токены недействительны
+type TokenCutoff struct {
+	UserID    uint32
+	NotBefore time.Time
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (r RefreshRequest) Valid() bool {
+	return r.RefreshToken != ""
+}
@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"unicode"
+	"unicode/utf8"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -14,7 +17,8 @@ func (s AuthRequest) Valid() bool {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
 type AuthData struct {
@@ -27,6 +31,45 @@ type User struct {
 	ID    uint32
 	Name  string
 	Coins uint32
+	Role  string
+}
+
+const (
+	RoleEmployee = "employee"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+)
+
+// ValidRole проверяет, что роль входит в список известных
+func ValidRole(role string) bool {
+	switch role {
+	case RoleEmployee, RoleSupport, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+const (
+	MinPasswordLength = 8
+	// bcrypt учитывает только первые 72 байта пароля, а Hash возвращает ошибку на более длинных
+	MaxPasswordBytes = 72
+)
+
+// StrongPassword проверяет, что пароль достаточно длинный и содержит буквы и цифры
+func StrongPassword(password string) bool {
+	if utf8.RuneCountInString(password) < MinPasswordLength || len(password) > MaxPasswordBytes {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
 }
 
 type Password string
@@ -33,3 +33,23 @@ func TestHash(t *testing.T) {
 		})
 	}
 }
+
+func TestStrongPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{name: "Letters and digits", password: "12345678M", want: true},
+		{name: "Cyrillic letters", password: "пароль2025", want: true},
+		{name: "Too short", password: "abc123", want: false},
+		{name: "Only digits", password: "1234567890", want: false},
+		{name: "Only letters", password: "password", want: false},
+		{name: "Longer than bcrypt limit", password: "a1" + string(make([]byte, 71)), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StrongPassword(tt.password))
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+//go:generate mockgen -source=allowance.go -destination=mock/allowance_mock.go -package=mock
+type AllowanceInterface interface {
+	Grant(ctx context.Context, grant entity.AllowanceGrant, role string, limit int) (int, error)
+	Preview(ctx context.Context, policy string, period time.Time, role string) ([]entity.AllowanceCredit, error)
+	ExpireDue(ctx context.Context, limit int) (int, error)
+	ListGrants(ctx context.Context, filter entity.AllowanceGrantFilter) ([]entity.AllowanceGrant, error)
+}
+
+type Allowance struct {
+	db DBInterface
+}
+
+func NewAllowance(db DBInterface) AllowanceInterface {
+	return &Allowance{db: db}
+}
+
+// eligibleUsers - пользователи с подходящей ролью ($3), которым еще ничего не начислено по политике $1 за период $2
+const eligibleUsers = `from "user" as u
+	where ($3::text='' or u.role=$3)
+		and not exists (select 1 from allowance_grant as g where g.policy=$1 and g.period=$2::date and g.user_id=u.id)`
+
+// Grant начисляет до limit пользователям сумму grant.Amount за период grant.Period и возвращает число начислений.
+// Уникальный ключ (policy, user_id, period) не дает начислить дважды, даже если обработчик запущен
+// на нескольких экземплярах сервиса.
+func (r *Allowance) Grant(ctx context.Context, grant entity.AllowanceGrant, role string, limit int) (int, error) {
+	query := `insert into allowance_grant(policy, user_id, period, amount, expires_at)
+		select $1, u.id, $2::date, $4, $5 ` + eligibleUsers + `
+		order by u.id
+		limit $6
+		on conflict (policy, user_id, period) do nothing
+		returning id, user_id;`
+	queryCredit := `update "user" set coins=coins+$1 where id=$2;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	rows, err := tx.Query(ctx, query, grant.Policy, grant.Period, role, grant.Amount, grant.ExpiresAt, limit)
+	if err != nil {
+		return 0, err
+	}
+	granted := []entity.AllowanceGrant{}
+	for rows.Next() {
+		g := grant
+		if err := rows.Scan(&g.ID, &g.UserID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		granted = append(granted, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	// пользователи блокируются по возрастанию id, как и при переводах
+	slices.SortFunc(granted, func(a, b entity.AllowanceGrant) int {
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+	for _, g := range granted {
+		_, err = tx.Exec(ctx, queryCredit, g.Amount, g.UserID)
+		if err != nil {
+			return 0, err
+		}
+		err = postLedger(ctx, tx, entity.LedgerTransaction{
+			Kind:        entity.LedgerAllowance,
+			ReferenceID: &g.ID,
+			Entries: []entity.LedgerEntry{
+				entity.SystemEntry(entity.AccountIssuance, -int64(g.Amount)),
+				entity.UserEntry(g.UserID, int64(g.Amount)),
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(granted), nil
+}
+
+// Preview возвращает пользователей, которым Grant начислил бы монеты по политике за период
+func (r *Allowance) Preview(ctx context.Context, policy string, period time.Time, role string) ([]entity.AllowanceCredit, error) {
+	query := `select u.id, u.name ` + eligibleUsers + ` order by u.id;`
+	res := []entity.AllowanceCredit{}
+	rows, err := r.db.Query(ctx, query, policy, period, role)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c entity.AllowanceCredit
+		if err := rows.Scan(&c.UserID, &c.User); err != nil {
+			return []entity.AllowanceCredit{}, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// ExpireDue списывает неизрасходованную часть наступивших к сгоранию начислений.
+// Монеты считаются потраченными в порядке поступления, поэтому от начисления остается
+// часть баланса за вычетом всех более поздних поступлений, но не больше самого начисления.
+func (r *Allowance) ExpireDue(ctx context.Context, limit int) (int, error) {
+	queryDue := `select id, user_id, amount, created_at from allowance_grant
+		where expired_at is null and expires_at<=NOW()
+		order by user_id, id
+		limit $1
+		for update skip locked;`
+	queryLock := `select coins from "user" where id=$1 for update;`
+	queryInflow := `select coalesce(sum(e.amount), 0)::bigint
+		from ledger_entry as e join ledger_transaction as t on t.id=e.transaction_id
+		where e.account='user' and e.user_id=$1 and e.amount>0 and t.created_at>$2;`
+	queryExpire := `update allowance_grant set expired_at=NOW(), expired_amount=$2 where id=$1;`
+	queryDebit := `update "user" set coins=coins-$1 where id=$2;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	rows, err := tx.Query(ctx, queryDue, limit)
+	if err != nil {
+		return 0, err
+	}
+	due := []entity.AllowanceGrant{}
+	for rows.Next() {
+		var g entity.AllowanceGrant
+		if err := rows.Scan(&g.ID, &g.UserID, &g.Amount, &g.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, g := range due {
+		var coins, inflow int64
+		if err := tx.QueryRow(ctx, queryLock, g.UserID).Scan(&coins); err != nil {
+			return 0, err
+		}
+		if err := tx.QueryRow(ctx, queryInflow, g.UserID, g.CreatedAt).Scan(&inflow); err != nil {
+			return 0, err
+		}
+		expired := min(max(coins-inflow, 0), int64(g.Amount))
+		if _, err := tx.Exec(ctx, queryExpire, g.ID, expired); err != nil {
+			return 0, err
+		}
+		if expired == 0 {
+			continue
+		}
+		if _, err := tx.Exec(ctx, queryDebit, expired, g.UserID); err != nil {
+			return 0, err
+		}
+		err = postLedger(ctx, tx, entity.LedgerTransaction{
+			Kind:        entity.LedgerAllowanceExpiry,
+			ReferenceID: &g.ID,
+			Entries: []entity.LedgerEntry{
+				entity.UserEntry(g.UserID, -expired),
+				entity.SystemEntry(entity.AccountIssuance, expired),
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(due), nil
+}
+
+// ListGrants возвращает последние начисления, отсортированные по убыванию id
+func (r *Allowance) ListGrants(ctx context.Context, filter entity.AllowanceGrantFilter) ([]entity.AllowanceGrant, error) {
+	query := `select g.id, g.policy, g.user_id, coalesce(u.name, ''), g.period, g.amount,
+				g.created_at, g.expires_at, g.expired_at, g.expired_amount
+			from allowance_grant as g
+			left join "user" as u on u.id=g.user_id
+			where true`
+	args := []interface{}{}
+	if filter.Policy != "" {
+		args = append(args, filter.Policy)
+		query += fmt.Sprintf(" and g.policy=$%d", len(args))
+	}
+	if filter.User != "" {
+		args = append(args, filter.User)
+		query += fmt.Sprintf(" and u.name=$%d", len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" order by g.id desc limit $%d;", len(args))
+
+	res := []entity.AllowanceGrant{}
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var g entity.AllowanceGrant
+		err := rows.Scan(&g.ID, &g.Policy, &g.UserID, &g.User, &g.Period, &g.Amount,
+			&g.CreatedAt, &g.ExpiresAt, &g.ExpiredAt, &g.ExpiredAmount)
+		if err != nil {
+			return []entity.AllowanceGrant{}, err
+		}
+		res = append(res, g)
+	}
+	return res, nil
+}
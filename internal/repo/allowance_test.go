@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowance_Grant(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewAllowance(mock)
+
+	query := `insert into allowance_grant\(policy, user_id, period, amount, expires_at\)`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	period := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2025, 11, 17, 0, 0, 0, 0, time.UTC)
+	grant := entity.AllowanceGrant{Policy: "monthly", Period: period, Amount: 200, ExpiresAt: &expiresAt}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).WithArgs("monthly", period, "employee", uint32(200), &expiresAt, 10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id"}).
+			AddRow(uint32(12), uint32(3)).
+			AddRow(uint32(11), uint32(1)))
+	// начисления проводятся по возрастанию id пользователя
+	for _, g := range []struct{ id, userId uint32 }{{11, 1}, {12, 3}} {
+		id := g.id
+		mock.ExpectExec(queryCredit).WithArgs(uint32(200), g.userId).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		expectLedger(mock, entity.LedgerAllowance, &id,
+			entity.SystemEntry(entity.AccountIssuance, -200),
+			entity.UserEntry(g.userId, 200)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	}
+	mock.ExpectCommit()
+
+	granted, err := repo.Grant(context.Background(), grant, "employee", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, granted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllowance_ExpireDue(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewAllowance(mock)
+
+	queryDue := `select id, user_id, amount, created_at from allowance_grant where expired_at is null and expires_at<=NOW\(\)`
+	queryLock := `select coins from "user" where id=\$1 for update;`
+	queryInflow := `select coalesce\(sum\(e.amount\), 0\)::bigint from ledger_entry as e`
+	queryExpire := `update allowance_grant set expired_at=NOW\(\), expired_amount=\$2 where id=\$1;`
+	queryDebit := `update "user" set coins=coins-\$1 where id=\$2;`
+	createdAt := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		coins   int64
+		inflow  int64
+		expired int64
+	}{
+		// баланс 1100, после начисления пришло 50: от начисления в 200 осталось все
+		{name: "Unspent", coins: 1100, inflow: 50, expired: 200},
+		// баланс 300, после начисления пришло 250: от начисления осталось 50
+		{name: "Partly spent", coins: 300, inflow: 250, expired: 50},
+		{name: "Spent", coins: 100, inflow: 250, expired: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uint32(7)
+			mock.ExpectBegin()
+			mock.ExpectQuery(queryDue).WithArgs(10).
+				WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "amount", "created_at"}).
+					AddRow(id, uint32(1), uint32(200), createdAt))
+			mock.ExpectQuery(queryLock).WithArgs(uint32(1)).
+				WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(tt.coins))
+			mock.ExpectQuery(queryInflow).WithArgs(uint32(1), createdAt).
+				WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(tt.inflow))
+			mock.ExpectExec(queryExpire).WithArgs(id, tt.expired).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			if tt.expired > 0 {
+				mock.ExpectExec(queryDebit).WithArgs(tt.expired, uint32(1)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				expectLedger(mock, entity.LedgerAllowanceExpiry, &id,
+					entity.UserEntry(1, -tt.expired),
+					entity.SystemEntry(entity.AccountIssuance, tt.expired)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+			}
+			mock.ExpectCommit()
+
+			processed, err := repo.ExpireDue(context.Background(), 10)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, processed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAllowance_ListGrants(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewAllowance(mock)
+
+	period := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`where true and u.name=\$1 order by g.id desc limit \$2;`).WithArgs("sofia", 50).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "policy", "user_id", "name", "period", "amount",
+			"created_at", "expires_at", "expired_at", "expired_amount"}).
+			AddRow(uint32(11), "monthly", uint32(1), "sofia", period, uint32(200),
+				period, (*time.Time)(nil), (*time.Time)(nil), uint32(0)))
+
+	res, err := repo.ListGrants(context.Background(), entity.AllowanceGrantFilter{User: "sofia", Limit: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.AllowanceGrant{{
+		ID: 11, Policy: "monthly", UserID: 1, User: "sofia", Period: period, Amount: 200, CreatedAt: period,
+	}}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
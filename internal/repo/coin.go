@@ -2,72 +2,209 @@ package repo
 
 import (
 	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
-	//"github.com/jackc/pgx/v5/pgxpool"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx"
+	pgx5 "github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
 )
 
 //go:generate mockgen -source=coin.go -destination=mock/coin_mock.go -package=mock
 type CoinInterface interface {
 	SendCoin(ctx context.Context, transaction entity.Transaction) error
+	SendCoinBatch(ctx context.Context, transactions []entity.Transaction) error
 	CheckBalance(ctx context.Context, id uint32) (uint32, error)
 	GetCoinHistory(ctx context.Context, id uint32) ([]entity.Transaction, error)
+	GetCoinHistoryPage(ctx context.Context, filter entity.CoinHistoryFilter) ([]entity.HistoryEntry, error)
+	GetRefunds(ctx context.Context, id uint32) ([]entity.Refund, error)
 }
 
+var (
+	ErrMixedSenders         = errors.New("batch transfer must have a single sender")
+	ErrInvalidLedgerBalance = errors.New("ledger balance is out of range")
+)
+
 type Coin struct {
-	db DBInterface
+	db     DBInterface
+	logger *zap.Logger
 }
 
-func NewCoin(db DBInterface) CoinInterface {
-	return &Coin{db: db}
+func NewCoin(db DBInterface, logger *zap.Logger) CoinInterface {
+	return &Coin{db: db, logger: logger}
 }
 
 func (u *Coin) SendCoin(ctx context.Context, trans entity.Transaction) error {
-	query := `insert into coin_history(from_user, to_user, amount, created_at) values ($1, $2, $3, NOW());`
-	query1 := `update "user" set coins=coins-$1 where id=$2;`
-	query2 := `update "user" set coins=coins+$1 where id=$2;`
+	return withRetry(ctx, u.logger, "SendCoin", func() error {
+		return u.sendCoins(ctx, []entity.Transaction{trans})
+	})
+}
+
+// SendCoinBatch выполняет переводы одного отправителя в одной транзакции:
+// либо проходят все, либо ни один
+func (u *Coin) SendCoinBatch(ctx context.Context, transactions []entity.Transaction) error {
+	return withRetry(ctx, u.logger, "SendCoinBatch", func() error {
+		return u.sendCoins(ctx, transactions)
+	})
+}
+
+func (u *Coin) sendCoins(ctx context.Context, transactions []entity.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	if _, err := transferTotal(transactions); err != nil {
+		return err
+	}
 	tx, err := u.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
-	_, err = tx.Exec(ctx, query, trans.From, trans.To, trans.Amount)
-	if err != nil {
+	if err := transferCoins(ctx, tx, transactions); err != nil {
 		return err
 	}
-	_, err = tx.Exec(ctx, query1, trans.Amount, trans.From)
-	if err != nil {
+	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
-	_, err = tx.Exec(ctx, query2, trans.Amount, trans.To)
+	return nil
+}
+
+// transferTotal проверяет, что у переводов один отправитель, и возвращает их общую сумму
+func transferTotal(transactions []entity.Transaction) (uint32, error) {
+	var total uint64
+	for _, trans := range transactions {
+		if trans.From != transactions[0].From {
+			return 0, ErrMixedSenders
+		}
+		total += uint64(trans.Amount)
+	}
+	// сумма больше uint32 заведомо превышает любой баланс
+	if total > math.MaxUint32 {
+		return 0, myErrors.NotEnoughCoinErr
+	}
+	return uint32(total), nil
+}
+
+// transferCoins выполняет переводы одного отправителя внутри переданной транзакции.
+// Ошибки NoUserErr и NotEnoughCoinErr возвращаются до первой записи, поэтому
+// после них транзакцию можно продолжать.
+func transferCoins(ctx context.Context, tx pgx5.Tx, transactions []entity.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	total, err := transferTotal(transactions)
 	if err != nil {
 		return err
 	}
-	if err := tx.Commit(ctx); err != nil {
+	from := transactions[0].From
+	ids := []uint32{from}
+	for _, trans := range transactions {
+		ids = append(ids, trans.To)
+	}
+	queryLock := `select id from "user" where id=$1 for update;`
+	queryDebit := `update "user" set coins=coins-$1 where id=$2 and coins>=$1 returning coins;`
+	queryCredit := `update "user" set coins=coins+$1 where id=$2;`
+	queryHistory := `insert into coin_history(from_user, to_user, amount, message, category, created_at)
+		values ($1, $2, $3, $4, $5, NOW()) returning id;`
+	// строки участников всегда блокируются по возрастанию id,
+	// иначе встречные переводы A->B и B->A могут взаимно заблокироваться
+	for _, id := range lockOrder(ids...) {
+		var locked uint32
+		err = tx.QueryRow(ctx, queryLock, id).Scan(&locked)
+		if err != nil {
+			if err.Error() == pgx.ErrNoRows.Error() {
+				return myErrors.NoUserErr
+			}
+			return err
+		}
+	}
+	// списание всей суммы и проверка баланса выполняются одним запросом под блокировкой строки,
+	// поэтому параллельные переводы не могут увести баланс в минус
+	var balance uint32
+	err = tx.QueryRow(ctx, queryDebit, total, from).Scan(&balance)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return myErrors.NotEnoughCoinErr
+		}
 		return err
 	}
+	for _, trans := range transactions {
+		_, err = tx.Exec(ctx, queryCredit, trans.Amount, trans.To)
+		if err != nil {
+			return err
+		}
+		var historyId uint32
+		err = tx.QueryRow(ctx, queryHistory, trans.From, trans.To, trans.Amount, trans.Message, trans.Category).Scan(&historyId)
+		if err != nil {
+			return err
+		}
+		err = postLedger(ctx, tx, entity.LedgerTransaction{
+			Kind:        entity.LedgerTransfer,
+			ReferenceID: &historyId,
+			Entries: []entity.LedgerEntry{
+				entity.UserEntry(trans.From, -int64(trans.Amount)),
+				entity.UserEntry(trans.To, int64(trans.Amount)),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// lockOrder возвращает уникальные id в порядке, в котором нужно блокировать строки
+func lockOrder(ids ...uint32) []uint32 {
+	res := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(res, id) {
+			res = append(res, id)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// CheckBalance считает баланс по журналу, а не по кэшу в "user".coins.
+// Монеты, удерживаемые на счете escrow до подтверждения перевода, в баланс не входят.
+// Отрицательная сумма означает, что журнал неполон (например, не перенесены
+// стартовые начисления старых пользователей), и возвращается как ошибка.
 func (u *Coin) CheckBalance(ctx context.Context, id uint32) (uint32, error) {
-	query := `select coins from "user" where id=$1;`
-	var res uint32
+	query := `select coalesce(sum(amount), 0)::bigint from ledger_entry where account='user' and user_id=$1;`
+	var res int64
 	err := u.db.QueryRow(ctx, query, id).Scan(&res)
 	if err != nil {
 		return 0, err
 	}
-	return res, nil
+	if res < 0 || res > math.MaxUint32 {
+		return 0, ErrInvalidLedgerBalance
+	}
+	return uint32(res), nil
 }
 
 func (u *Coin) GetCoinHistory(ctx context.Context, id uint32) ([]entity.Transaction, error) {
-	query := `select from_user, to_user, amount from coin_history where from_user=$1 OR to_user=$1;`
+	// имена участников подтягиваются тем же запросом; после удаления пользователя
+	// from_user/to_user становятся NULL, поэтому они приводятся к 0 и пустому имени
+	query := `select h.id, coalesce(h.from_user, 0), coalesce(h.to_user, 0), coalesce(f.name, ''), coalesce(t.name, ''), h.amount, h.message, h.category, h.created_at
+			from coin_history as h
+			left join "user" as f on h.from_user=f.id
+			left join "user" as t on h.to_user=t.id
+			where h.from_user=$1 OR h.to_user=$1
+			order by h.id desc;`
 	res := []entity.Transaction{}
 	rows, err := u.db.Query(ctx, query, id)
 	if err != nil {
 		return res, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var t entity.Transaction
-		err := rows.Scan(&t.From, &t.To, &t.Amount)
+		err := rows.Scan(&t.ID, &t.From, &t.To, &t.FromName, &t.ToName, &t.Amount, &t.Message, &t.Category, &t.CreatedAt)
 		if err != nil {
 			return []entity.Transaction{}, err
 		}
@@ -75,3 +212,86 @@ func (u *Coin) GetCoinHistory(ctx context.Context, id uint32) ([]entity.Transact
 	}
 	return res, nil
 }
+
+// GetRefunds возвращает возвраты покупок пользователя, начиная с последних
+func (u *Coin) GetRefunds(ctx context.Context, id uint32) ([]entity.Refund, error) {
+	query := `select id, coalesce(inventory_id, 0), amount, created_at from refund where user_id=$1 order by id desc;`
+	res := []entity.Refund{}
+	rows, err := u.db.Query(ctx, query, id)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r entity.Refund
+		err := rows.Scan(&r.ID, &r.InventoryID, &r.Amount, &r.CreatedAt)
+		if err != nil {
+			return []entity.Refund{}, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// likeEscaper экранирует символы шаблона LIKE, чтобы поиск шел по точной подстроке
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GetCoinHistoryPage возвращает страницу истории, отсортированную по убыванию id.
+// Пагинация по ключу: следующая страница начинается с id меньше filter.Cursor.
+func (u *Coin) GetCoinHistoryPage(ctx context.Context, filter entity.CoinHistoryFilter) ([]entity.HistoryEntry, error) {
+	query := `select h.id,
+				case when h.from_user=$1 then 'sent' else 'received' end,
+				coalesce(case when h.from_user=$1 then t.name else f.name end, ''),
+				h.amount, h.message, h.category, h.created_at
+			from coin_history as h
+			left join "user" as f on h.from_user=f.id
+			left join "user" as t on h.to_user=t.id
+			where (h.from_user=$1 or h.to_user=$1)`
+	args := []interface{}{filter.UserID}
+	addArg := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" and "+cond, len(args))
+	}
+	if filter.Cursor > 0 {
+		addArg("h.id<$%d", filter.Cursor)
+	}
+	switch filter.Direction {
+	case entity.DirectionSent:
+		query += " and h.from_user=$1"
+	case entity.DirectionReceived:
+		query += " and h.to_user=$1 and h.from_user is distinct from $1"
+	}
+	if filter.Counterparty != "" {
+		addArg("(case when h.from_user=$1 then t.name else f.name end)=$%d", filter.Counterparty)
+	}
+	if filter.From != nil {
+		addArg("h.created_at>=$%d", *filter.From)
+	}
+	if filter.To != nil {
+		addArg("h.created_at<$%d", *filter.To)
+	}
+	if filter.Category != "" {
+		addArg("h.category=$%d", filter.Category)
+	}
+	if filter.Search != "" {
+		addArg(`h.message ilike '%%' || $%d || '%%'`, likeEscaper.Replace(filter.Search))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" order by h.id desc limit $%d;", len(args))
+
+	res := []entity.HistoryEntry{}
+	rows, err := u.db.Query(ctx, query, args...)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e entity.HistoryEntry
+		err := rows.Scan(&e.ID, &e.Direction, &e.Counterparty, &e.Amount, &e.Message, &e.Category, &e.CreatedAt)
+		if err != nil {
+			return []entity.HistoryEntry{}, err
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
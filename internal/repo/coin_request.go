@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+
+	"github.com/jackc/pgx"
+	pgx5 "github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=coin_request.go -destination=mock/coin_request_mock.go -package=mock
+type CoinRequestInterface interface {
+	Create(ctx context.Context, req entity.CoinRequest) (entity.CoinRequest, error)
+	List(ctx context.Context, userId uint32, direction string) ([]entity.CoinRequest, error)
+	Accept(ctx context.Context, payerId uint32, id uint32) (entity.CoinRequest, error)
+	Decline(ctx context.Context, payerId uint32, id uint32) (entity.CoinRequest, error)
+}
+
+type CoinRequest struct {
+	db     DBInterface
+	logger *zap.Logger
+}
+
+func NewCoinRequest(db DBInterface, logger *zap.Logger) CoinRequestInterface {
+	return &CoinRequest{db: db, logger: logger}
+}
+
+// coinRequestColumns выбирает запрос из CTE r вместе с именами участников.
+// Pending-запрос после expires_at отдается со статусом expired.
+const coinRequestColumns = `select r.id, r.requester, coalesce(req.name, ''), r.payer, coalesce(p.name, ''),
+		r.amount, r.message, r.category,
+		case when r.status='pending' and r.expires_at<=NOW() then 'expired' else r.status end,
+		r.created_at, r.expires_at, r.resolved_at
+	from r left join "user" as req on req.id=r.requester left join "user" as p on p.id=r.payer`
+
+func scanCoinRequest(row pgx5.Row) (entity.CoinRequest, error) {
+	var c entity.CoinRequest
+	err := row.Scan(&c.ID, &c.RequesterID, &c.Requester, &c.PayerID, &c.Payer,
+		&c.Amount, &c.Message, &c.Category, &c.Status, &c.CreatedAt, &c.ExpiresAt, &c.ResolvedAt)
+	return c, err
+}
+
+func (r *CoinRequest) Create(ctx context.Context, req entity.CoinRequest) (entity.CoinRequest, error) {
+	query := `with r as (
+			insert into coin_request(requester, payer, amount, message, category, expires_at)
+			values ($1, $2, $3, $4, $5, $6) returning *
+		) ` + coinRequestColumns + `;`
+	return scanCoinRequest(r.db.QueryRow(ctx, query,
+		req.RequesterID, req.PayerID, req.Amount, req.Message, req.Category, req.ExpiresAt))
+}
+
+// List возвращает входящие (direction=incoming), исходящие (outgoing) или все запросы пользователя
+func (r *CoinRequest) List(ctx context.Context, userId uint32, direction string) ([]entity.CoinRequest, error) {
+	where := "requester=$1 or payer=$1"
+	switch direction {
+	case entity.CoinRequestsIncoming:
+		where = "payer=$1"
+	case entity.CoinRequestsOutgoing:
+		where = "requester=$1"
+	}
+	query := `with r as (select * from coin_request where ` + where + `) ` + coinRequestColumns + ` order by r.id desc;`
+	res := []entity.CoinRequest{}
+	rows, err := r.db.Query(ctx, query, userId)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c, err := scanCoinRequest(rows)
+		if err != nil {
+			return []entity.CoinRequest{}, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// Accept оплачивает открытый запрос плательщика: статус меняется на accepted и монеты
+// переводятся автору запроса в одной транзакции. Если перевод не прошел, запрос
+// остается открытым, а параллельные подтверждения ждут блокировку строки и не оплатят его дважды.
+func (r *CoinRequest) Accept(ctx context.Context, payerId uint32, id uint32) (entity.CoinRequest, error) {
+	var res entity.CoinRequest
+	err := withRetry(ctx, r.logger, "AcceptCoinRequest", func() error {
+		var err error
+		res, err = r.accept(ctx, payerId, id)
+		return err
+	})
+	return res, err
+}
+
+func (r *CoinRequest) accept(ctx context.Context, payerId uint32, id uint32) (entity.CoinRequest, error) {
+	query := `with r as (
+			update coin_request set status='accepted', resolved_at=NOW()
+			where id=$1 and payer=$2 and status='pending' and expires_at>NOW() returning *
+		) ` + coinRequestColumns + `;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.CoinRequest{}, err
+	}
+	defer tx.Rollback(ctx)
+	res, err := scanCoinRequest(tx.QueryRow(ctx, query, id, payerId))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.CoinRequest{}, myErrors.NoCoinRequestErr
+		}
+		return entity.CoinRequest{}, err
+	}
+	note := res.Note()
+	err = transferCoins(ctx, tx, []entity.Transaction{{
+		From:     res.PayerID,
+		To:       res.RequesterID,
+		Amount:   res.Amount,
+		Message:  note.Message,
+		Category: note.Category,
+	}})
+	if err != nil {
+		return entity.CoinRequest{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.CoinRequest{}, err
+	}
+	return res, nil
+}
+
+func (r *CoinRequest) Decline(ctx context.Context, payerId uint32, id uint32) (entity.CoinRequest, error) {
+	query := `with r as (
+			update coin_request set status='declined', resolved_at=NOW()
+			where id=$1 and payer=$2 and status='pending' and expires_at>NOW() returning *
+		) ` + coinRequestColumns + `;`
+	return r.resolve(ctx, query, id, payerId)
+}
+
+func (r *CoinRequest) resolve(ctx context.Context, query string, id uint32, payerId uint32) (entity.CoinRequest, error) {
+	res, err := scanCoinRequest(r.db.QueryRow(ctx, query, id, payerId))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.CoinRequest{}, myErrors.NoCoinRequestErr
+		}
+		return entity.CoinRequest{}, err
+	}
+	return res, nil
+}
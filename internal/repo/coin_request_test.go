@@ -0,0 +1,160 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var coinRequestRows = []string{"id", "requester", "requester_name", "payer", "payer_name", "amount", "message", "category",
+	"status", "created_at", "expires_at", "resolved_at"}
+
+func TestCoinRequest_List(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewCoinRequest(mock, zap.NewNop())
+
+	createdAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(7 * 24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		direction string
+		query     string
+		mock      func(m pgxmock.PgxPoolIface, query string)
+		want      []entity.CoinRequest
+		err       error
+	}{
+		{
+			name:      "Incoming",
+			direction: entity.CoinRequestsIncoming,
+			query:     `with r as \(select \* from coin_request where payer=\$1\)`,
+			mock: func(m pgxmock.PgxPoolIface, query string) {
+				m.ExpectQuery(query).WithArgs(uint32(1)).
+					WillReturnRows(pgxmock.NewRows(coinRequestRows).
+						AddRow(uint32(3), uint32(2), "mary", uint32(1), "sofia", uint32(10), "за обед", "lunch",
+							"expired", createdAt, expiresAt, (*time.Time)(nil)))
+			},
+			want: []entity.CoinRequest{{
+				ID: 3, RequesterID: 2, Requester: "mary", PayerID: 1, Payer: "sofia", Amount: 10,
+				Message: "за обед", Category: "lunch", Status: entity.CoinRequestExpired, CreatedAt: createdAt, ExpiresAt: expiresAt,
+			}},
+			err: nil,
+		},
+		{
+			name:      "All, db error",
+			direction: "",
+			query:     `with r as \(select \* from coin_request where requester=\$1 or payer=\$1\)`,
+			mock: func(m pgxmock.PgxPoolIface, query string) {
+				m.ExpectQuery(query).WithArgs(uint32(1)).WillReturnError(ErrDB)
+			},
+			want: []entity.CoinRequest{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock, tt.query)
+			res, err := repo.List(context.Background(), 1, tt.direction)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCoinRequest_Accept(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewCoinRequest(mock, zap.NewNop())
+
+	queryAccept := `update coin_request set status='accepted', resolved_at=NOW\(\) where id=\$1 and payer=\$2 and status='pending' and expires_at>NOW\(\)`
+	queryLock := `select id from "user" where id=\$1 for update;`
+	queryDebit := `update "user" set coins=coins-\$1 where id=\$2 and coins>=\$1 returning coins;`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	queryHistory := `insert into coin_history`
+	createdAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	resolvedAt := createdAt.Add(time.Hour)
+	accepted := func() *pgxmock.Rows {
+		return pgxmock.NewRows(coinRequestRows).
+			AddRow(uint32(3), uint32(2), "mary", uint32(1), "sofia", uint32(10), "за обед", "",
+				"accepted", createdAt, createdAt.Add(time.Hour*24), &resolvedAt)
+	}
+	lockUsers := func(m pgxmock.PgxPoolIface) {
+		m.ExpectQuery(queryLock).WithArgs(uint32(1)).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(1)))
+		m.ExpectQuery(queryLock).WithArgs(uint32(2)).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)))
+	}
+	historyId := uint32(8)
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryAccept).WithArgs(uint32(3), uint32(1)).WillReturnRows(accepted())
+				lockUsers(m)
+				m.ExpectQuery(queryDebit).WithArgs(uint32(10), uint32(1)).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(90)))
+				m.ExpectExec(queryCredit).WithArgs(uint32(10), uint32(2)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryHistory).WithArgs(uint32(1), uint32(2), uint32(10), "за обед", "").
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyId))
+				expectLedger(m, entity.LedgerTransfer, &historyId,
+					entity.UserEntry(1, -10), entity.UserEntry(2, 10)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, not enough coins keeps the request pending",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryAccept).WithArgs(uint32(3), uint32(1)).WillReturnRows(accepted())
+				lockUsers(m)
+				m.ExpectQuery(queryDebit).WithArgs(uint32(10), uint32(1)).WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Fail, closed or expired",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryAccept).WithArgs(uint32(3), uint32(1)).WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NoCoinRequestErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.Accept(context.Background(), 1, 3)
+			assert.Equal(t, tt.err, err)
+			if err == nil {
+				assert.Equal(t, entity.CoinRequestAccepted, res.Status)
+				assert.Equal(t, uint32(2), res.RequesterID)
+				assert.Equal(t, &resolvedAt, res.ResolvedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
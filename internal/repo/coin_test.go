@@ -2,11 +2,17 @@ package repo
 
 import (
 	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 )
 
 func TestCoin_CheckBalance(t *testing.T) {
@@ -16,9 +22,9 @@ func TestCoin_CheckBalance(t *testing.T) {
 	}
 	defer mock.Close()
 
-	repo := NewCoin(mock)
+	repo := NewCoin(mock, zap.NewNop())
 	id := uint32(1)
-	query := `select coins from "user" where id=\$1;`
+	query := `select coalesce\(sum\(amount\), 0\)::bigint from ledger_entry where account='user' and user_id=\$1;`
 
 	tests := []struct {
 		name string
@@ -30,11 +36,20 @@ func TestCoin_CheckBalance(t *testing.T) {
 			name: "Success",
 			mock: func(m pgxmock.PgxPoolIface, query string, id uint32) {
 				m.ExpectQuery(query).WithArgs(id).
-					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(1000)))
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(int64(1000)))
 			},
 			want: 1000,
 			err:  nil,
 		},
+		{
+			name: "Fail, negative balance",
+			mock: func(m pgxmock.PgxPoolIface, query string, id uint32) {
+				m.ExpectQuery(query).WithArgs(id).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(int64(-50)))
+			},
+			want: 0,
+			err:  ErrInvalidLedgerBalance,
+		},
 		{
 			name: "Fail",
 			mock: func(m pgxmock.PgxPoolIface, query string, id uint32) {
@@ -63,8 +78,14 @@ func TestCoin_GetCoinHistory(t *testing.T) {
 	}
 	defer mock.Close()
 
-	repo := NewCoin(mock)
-	query := `select from_user, to_user, amount from coin_history where from_user=\$1 OR to_user=\$1;`
+	repo := NewCoin(mock, zap.NewNop())
+	query := `select h.id, coalesce\(h.from_user, 0\), coalesce\(h.to_user, 0\), coalesce\(f.name, ''\), coalesce\(t.name, ''\), h.amount, h.message, h.category, h.created_at
+	from coin_history as h
+	left join "user" as f on h.from_user=f.id
+	left join "user" as t on h.to_user=t.id
+	where h.from_user=\$1 OR h.to_user=\$1
+	order by h.id desc;`
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
 	id := uint32(1)
 
 	tests := []struct {
@@ -77,21 +98,23 @@ func TestCoin_GetCoinHistory(t *testing.T) {
 			name: "Success",
 			mock: func(m pgxmock.PgxPoolIface, query string, id uint32) {
 				m.ExpectQuery(query).WithArgs(id).WillReturnRows(
-					pgxmock.NewRows([]string{"from_user", "to_user", "amount"}).
-						AddRow(uint32(1), uint32(2), uint32(100)).
-						AddRow(uint32(3), uint32(1), uint32(50)))
+					pgxmock.NewRows([]string{"id", "from_user", "to_user", "from_name", "to_name", "amount", "message", "category", "created_at"}).
+						AddRow(uint32(3), uint32(1), uint32(2), "sofia", "mary", uint32(100), "спасибо", "thanks", createdAt).
+						AddRow(uint32(2), uint32(3), uint32(1), "john", "sofia", uint32(50), "", "", createdAt).
+						AddRow(uint32(1), uint32(0), uint32(1), "", "sofia", uint32(20), "", "", createdAt))
 			},
 			err: nil,
 			want: []entity.Transaction{
-				{From: 1, To: 2, Amount: 100},
-				{From: 3, To: 1, Amount: 50},
+				{ID: 3, From: 1, To: 2, FromName: "sofia", ToName: "mary", Amount: 100, Message: "спасибо", Category: "thanks", CreatedAt: createdAt},
+				{ID: 2, From: 3, To: 1, FromName: "john", ToName: "sofia", Amount: 50, CreatedAt: createdAt},
+				{ID: 1, From: 0, To: 1, FromName: "", ToName: "sofia", Amount: 20, CreatedAt: createdAt},
 			},
 		},
 		{
 			name: "Success, but empty",
 			mock: func(m pgxmock.PgxPoolIface, query string, id uint32) {
 				m.ExpectQuery(query).WithArgs(id).WillReturnRows(
-					pgxmock.NewRows([]string{"from_user", "to_user", "amount"}))
+					pgxmock.NewRows([]string{"id", "from_user", "to_user", "from_name", "to_name", "amount", "message", "category", "created_at"}))
 			},
 			err:  nil,
 			want: []entity.Transaction{},
@@ -115,3 +138,358 @@ func TestCoin_GetCoinHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestCoin_GetRefunds(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	repo := NewCoin(mock, zap.NewNop())
+	query := `select id, coalesce\(inventory_id, 0\), amount, created_at from refund where user_id=\$1 order by id desc;`
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	id := uint32(1)
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		err  error
+		want []entity.Refund
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(id).WillReturnRows(
+					pgxmock.NewRows([]string{"id", "inventory_id", "amount", "created_at"}).
+						AddRow(uint32(2), uint32(10), uint32(80), createdAt).
+						AddRow(uint32(1), uint32(0), uint32(20), createdAt))
+			},
+			err: nil,
+			want: []entity.Refund{
+				{ID: 2, InventoryID: 10, Amount: 80, CreatedAt: createdAt},
+				{ID: 1, InventoryID: 0, Amount: 20, CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "Fail",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(id).WillReturnError(ErrDB)
+			},
+			err:  ErrDB,
+			want: []entity.Refund{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.GetRefunds(context.Background(), id)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestCoin_SendCoin(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewCoin(mock, zap.NewNop())
+
+	queryLock := `select id from "user" where id=\$1 for update;`
+	queryDebit := `update "user" set coins=coins-\$1 where id=\$2 and coins>=\$1 returning coins;`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	queryHistory := `insert into coin_history\(from_user, to_user, amount, message, category, created_at\)
+		values \(\$1, \$2, \$3, \$4, \$5, NOW\(\)\) returning id;`
+	trans := entity.Transaction{From: 1, To: 2, Amount: 100, Message: "спасибо за ревью", Category: "thanks"}
+	historyId := uint32(10)
+	transfer := []entity.LedgerEntry{
+		entity.UserEntry(trans.From, -int64(trans.Amount)),
+		entity.UserEntry(trans.To, int64(trans.Amount)),
+	}
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.From))
+				m.ExpectQuery(queryLock).WithArgs(trans.To).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.To))
+				m.ExpectQuery(queryDebit).WithArgs(trans.Amount, trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(900)))
+				m.ExpectExec(queryCredit).WithArgs(trans.Amount, trans.To).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryHistory).WithArgs(trans.From, trans.To, trans.Amount, trans.Message, trans.Category).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyId))
+				expectLedger(m, entity.LedgerTransfer, &historyId, transfer...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, not enough coins",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.From))
+				m.ExpectQuery(queryLock).WithArgs(trans.To).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.To))
+				m.ExpectQuery(queryDebit).WithArgs(trans.Amount, trans.From).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Fail, no destination user",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.From))
+				m.ExpectQuery(queryLock).WithArgs(trans.To).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NoUserErr,
+		},
+		{
+			name: "Success after deadlock",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(trans.From).
+					WillReturnError(&pgconn.PgError{Code: "40P01"})
+				m.ExpectRollback()
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.From))
+				m.ExpectQuery(queryLock).WithArgs(trans.To).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.To))
+				m.ExpectQuery(queryDebit).WithArgs(trans.Amount, trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(900)))
+				m.ExpectExec(queryCredit).WithArgs(trans.Amount, trans.To).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryHistory).WithArgs(trans.From, trans.To, trans.Amount, trans.Message, trans.Category).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyId))
+				expectLedger(m, entity.LedgerTransfer, &historyId, transfer...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.From))
+				m.ExpectQuery(queryLock).WithArgs(trans.To).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(trans.To))
+				m.ExpectQuery(queryDebit).WithArgs(trans.Amount, trans.From).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(900)))
+				m.ExpectExec(queryCredit).WithArgs(trans.Amount, trans.To).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryHistory).WithArgs(trans.From, trans.To, trans.Amount, trans.Message, trans.Category).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyId))
+				expectLedger(m, entity.LedgerTransfer, &historyId, transfer...).
+					WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			err: ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			err := repo.SendCoin(context.Background(), trans)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCoin_SendCoinBatch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewCoin(mock, zap.NewNop())
+
+	queryLock := `select id from "user" where id=\$1 for update;`
+	queryDebit := `update "user" set coins=coins-\$1 where id=\$2 and coins>=\$1 returning coins;`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	queryHistory := `insert into coin_history\(from_user, to_user, amount, message, category, created_at\)`
+	// получатели идут не по порядку id, блокировки все равно берутся по возрастанию
+	batch := []entity.Transaction{
+		{From: 2, To: 3, Amount: 100, Message: "спасибо"},
+		{From: 2, To: 1, Amount: 50},
+	}
+	tests := []struct {
+		name  string
+		batch []entity.Transaction
+		mock  func(m pgxmock.PgxPoolIface)
+		err   error
+	}{
+		{
+			name:  "Success",
+			batch: batch,
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				for _, id := range []uint32{1, 2, 3} {
+					m.ExpectQuery(queryLock).WithArgs(id).
+						WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(id))
+				}
+				m.ExpectQuery(queryDebit).WithArgs(uint32(150), uint32(2)).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(850)))
+				for i, trans := range batch {
+					historyId := uint32(10 + i)
+					m.ExpectExec(queryCredit).WithArgs(trans.Amount, trans.To).
+						WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+					m.ExpectQuery(queryHistory).WithArgs(trans.From, trans.To, trans.Amount, trans.Message, trans.Category).
+						WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyId))
+					expectLedger(m, entity.LedgerTransfer, &historyId,
+						entity.UserEntry(trans.From, -int64(trans.Amount)),
+						entity.UserEntry(trans.To, int64(trans.Amount))).
+						WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				}
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name:  "Fail, not enough coins for total",
+			batch: batch,
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				for _, id := range []uint32{1, 2, 3} {
+					m.ExpectQuery(queryLock).WithArgs(id).
+						WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(id))
+				}
+				m.ExpectQuery(queryDebit).WithArgs(uint32(150), uint32(2)).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Fail, total overflows",
+			batch: []entity.Transaction{
+				{From: 2, To: 3, Amount: math.MaxUint32},
+				{From: 2, To: 1, Amount: 1},
+			},
+			mock: func(m pgxmock.PgxPoolIface) {},
+			err:  myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Fail, several senders",
+			batch: []entity.Transaction{
+				{From: 2, To: 3, Amount: 1},
+				{From: 1, To: 3, Amount: 1},
+			},
+			mock: func(m pgxmock.PgxPoolIface) {},
+			err:  ErrMixedSenders,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			err := repo.SendCoinBatch(context.Background(), tt.batch)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCoin_GetCoinHistoryPage(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewCoin(mock, zap.NewNop())
+
+	createdAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "direction", "counterparty", "amount", "message", "category", "created_at"}
+	tests := []struct {
+		name   string
+		filter entity.CoinHistoryFilter
+		mock   func(m pgxmock.PgxPoolIface)
+		want   []entity.HistoryEntry
+		err    error
+	}{
+		{
+			name:   "Success, without filters",
+			filter: entity.CoinHistoryFilter{UserID: 1, Limit: 2},
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(`where \(h.from_user=\$1 or h.to_user=\$1\) order by h.id desc limit \$2;`).
+					WithArgs(uint32(1), 2).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(uint32(5), "sent", "mary", uint32(100), "спасибо", "thanks", createdAt).
+						AddRow(uint32(3), "received", "john", uint32(50), "", "", createdAt))
+			},
+			want: []entity.HistoryEntry{
+				{ID: 5, Direction: "sent", Counterparty: "mary", Amount: 100, Message: "спасибо", Category: "thanks", CreatedAt: createdAt},
+				{ID: 3, Direction: "received", Counterparty: "john", Amount: 50, CreatedAt: createdAt},
+			},
+			err: nil,
+		},
+		{
+			name: "Success, with cursor, direction, counterparty and dates",
+			filter: entity.CoinHistoryFilter{
+				UserID: 1, Cursor: 5, Limit: 10, Direction: entity.DirectionSent,
+				Counterparty: "mary", From: &createdAt, To: &createdAt,
+			},
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(`and h.id<\$2 and h.from_user=\$1 and \(case when h.from_user=\$1 then t.name else f.name end\)=\$3 `+
+					`and h.created_at>=\$4 and h.created_at<\$5 order by h.id desc limit \$6;`).
+					WithArgs(uint32(1), uint32(5), "mary", createdAt, createdAt, 10).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(uint32(4), "sent", "mary", uint32(10), "", "", createdAt))
+			},
+			want: []entity.HistoryEntry{
+				{ID: 4, Direction: "sent", Counterparty: "mary", Amount: 10, CreatedAt: createdAt},
+			},
+			err: nil,
+		},
+		{
+			name:   "Success, with category and search",
+			filter: entity.CoinHistoryFilter{UserID: 1, Limit: 10, Category: "thanks", Search: "100%_done"},
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(`and h.category=\$2 and h.message ilike '%' \|\| \$3 \|\| '%' order by h.id desc limit \$4;`).
+					WithArgs(uint32(1), "thanks", `100\%\_done`, 10).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(uint32(7), "received", "john", uint32(10), "100%_done", "thanks", createdAt))
+			},
+			want: []entity.HistoryEntry{
+				{ID: 7, Direction: "received", Counterparty: "john", Amount: 10, Message: "100%_done", Category: "thanks", CreatedAt: createdAt},
+			},
+			err: nil,
+		},
+		{
+			name:   "Fail",
+			filter: entity.CoinHistoryFilter{UserID: 1, Limit: 2},
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(`from coin_history as h`).WithArgs(uint32(1), 2).WillReturnError(ErrDB)
+			},
+			want: []entity.HistoryEntry{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.GetCoinHistoryPage(context.Background(), tt.filter)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
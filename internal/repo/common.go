@@ -3,9 +3,12 @@ package repo
 import (
 	"context"
 	"errors"
+	"math/rand"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
 )
 
 // Интерфейс для пула соединений или транзакции
@@ -17,3 +20,64 @@ type DBInterface interface {
 }
 
 var ErrDB = errors.New("Some db err")
+
+const uniqueViolationCode = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+const (
+	maxTxRetries     = 5
+	txRetryBaseDelay = 10 * time.Millisecond
+)
+
+// Коды ошибок postgres, при которых транзакцию можно безопасно повторить
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailureCode || pgErr.Code == deadlockDetectedCode
+}
+
+// withRetry выполняет транзакцию fn и повторяет ее с экспоненциальной задержкой
+// при ошибках сериализации и взаимоблокировках
+func withRetry(ctx context.Context, logger *zap.Logger, op string, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxTxRetries; attempt++ {
+		if attempt > 0 {
+			delay := txRetryBaseDelay << (attempt - 1)
+			delay += time.Duration(rand.Int63n(int64(delay)))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		err = fn()
+		if !isRetryable(err) {
+			if attempt > 0 {
+				if err == nil {
+					logger.Info("transaction succeeded after retries",
+						zap.String("op", op), zap.Int("retries", attempt))
+				} else {
+					logger.Warn("transaction failed after retries",
+						zap.String("op", op), zap.Int("retries", attempt), zap.Error(err))
+				}
+			}
+			return err
+		}
+		logger.Warn("transaction conflict",
+			zap.String("op", op), zap.Int("attempt", attempt+1), zap.Error(err))
+	}
+	logger.Error("transaction retries exhausted",
+		zap.String("op", op), zap.Int("retries", maxTxRetries), zap.Error(err))
+	return err
+}
@@ -0,0 +1,246 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx"
+	pgx5 "github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=escrow.go -destination=mock/escrow_mock.go -package=mock
+type EscrowInterface interface {
+	Hold(ctx context.Context, transfer entity.PendingTransfer) (entity.PendingTransfer, error)
+	List(ctx context.Context, userId uint32, direction string) ([]entity.PendingTransfer, error)
+	Resolve(ctx context.Context, userId uint32, id uint32, accept bool) (entity.PendingTransfer, error)
+	ReturnExpired(ctx context.Context, limit int) (int, error)
+}
+
+type Escrow struct {
+	db     DBInterface
+	logger *zap.Logger
+}
+
+func NewEscrow(db DBInterface, logger *zap.Logger) EscrowInterface {
+	return &Escrow{db: db, logger: logger}
+}
+
+// pendingColumns выбирает перевод из CTE p вместе с именами участников.
+// После удаления пользователя from_user/to_user становятся NULL и приводятся к 0.
+const pendingColumns = `select p.id, coalesce(p.from_user, 0), coalesce(f.name, ''), coalesce(p.to_user, 0), coalesce(t.name, ''),
+		p.amount, p.message, p.category, p.status, p.created_at, p.expires_at, p.resolved_at
+	from p left join "user" as f on f.id=p.from_user left join "user" as t on t.id=p.to_user`
+
+func scanPending(row pgx5.Row) (entity.PendingTransfer, error) {
+	var p entity.PendingTransfer
+	err := row.Scan(&p.ID, &p.FromUserID, &p.FromUser, &p.ToUserID, &p.ToUser,
+		&p.Amount, &p.Message, &p.Category, &p.Status, &p.CreatedAt, &p.ExpiresAt, &p.ResolvedAt)
+	return p, err
+}
+
+// Hold списывает монеты с отправителя на счет escrow и создает ожидающий перевод
+func (r *Escrow) Hold(ctx context.Context, transfer entity.PendingTransfer) (entity.PendingTransfer, error) {
+	var res entity.PendingTransfer
+	err := withRetry(ctx, r.logger, "Hold", func() error {
+		var err error
+		res, err = r.hold(ctx, transfer)
+		return err
+	})
+	return res, err
+}
+
+func (r *Escrow) hold(ctx context.Context, transfer entity.PendingTransfer) (entity.PendingTransfer, error) {
+	queryDebit := `update "user" set coins=coins-$1 where id=$2 and coins>=$1 returning coins;`
+	queryInsert := `with p as (
+			insert into pending_transfer(from_user, to_user, amount, message, category, expires_at)
+			values ($1, $2, $3, $4, $5, $6) returning *
+		) ` + pendingColumns + `;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	defer tx.Rollback(ctx)
+	var balance uint32
+	err = tx.QueryRow(ctx, queryDebit, transfer.Amount, transfer.FromUserID).Scan(&balance)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.PendingTransfer{}, myErrors.NotEnoughCoinErr
+		}
+		return entity.PendingTransfer{}, err
+	}
+	res, err := scanPending(tx.QueryRow(ctx, queryInsert, transfer.FromUserID, transfer.ToUserID,
+		transfer.Amount, transfer.Message, transfer.Category, transfer.ExpiresAt))
+	if err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	err = postLedger(ctx, tx, entity.LedgerTransaction{
+		Kind:        entity.LedgerEscrowHold,
+		ReferenceID: &res.ID,
+		Entries: []entity.LedgerEntry{
+			entity.UserEntry(res.FromUserID, -int64(res.Amount)),
+			entity.SystemEntry(entity.AccountEscrow, int64(res.Amount)),
+		},
+	})
+	if err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	return res, nil
+}
+
+// List возвращает отправленные (direction=sent), полученные (received) или все ожидающие переводы пользователя
+func (r *Escrow) List(ctx context.Context, userId uint32, direction string) ([]entity.PendingTransfer, error) {
+	where := "from_user=$1 or to_user=$1"
+	switch direction {
+	case entity.DirectionSent:
+		where = "from_user=$1"
+	case entity.DirectionReceived:
+		where = "to_user=$1"
+	}
+	query := `with p as (select * from pending_transfer where ` + where + `) ` + pendingColumns + ` order by p.id desc;`
+	res := []entity.PendingTransfer{}
+	rows, err := r.db.Query(ctx, query, userId)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p, err := scanPending(rows)
+		if err != nil {
+			return []entity.PendingTransfer{}, err
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// Resolve закрывает удерживаемый перевод по решению получателя: при принятии монеты
+// зачисляются получателю и перевод попадает в историю, при отказе возвращаются отправителю
+func (r *Escrow) Resolve(ctx context.Context, userId uint32, id uint32, accept bool) (entity.PendingTransfer, error) {
+	var res entity.PendingTransfer
+	err := withRetry(ctx, r.logger, "Resolve", func() error {
+		var err error
+		res, err = r.resolve(ctx, userId, id, accept)
+		return err
+	})
+	return res, err
+}
+
+func (r *Escrow) resolve(ctx context.Context, userId uint32, id uint32, accept bool) (entity.PendingTransfer, error) {
+	queryResolve := `with p as (
+			update pending_transfer set status=$3, resolved_at=NOW()
+			where id=$1 and to_user=$2 and status='held' and expires_at>NOW() returning *
+		) ` + pendingColumns + `;`
+	queryHistory := `insert into coin_history(from_user, to_user, amount, message, category, created_at)
+		values (nullif($1, 0), $2, $3, $4, $5, NOW());`
+	status := entity.EscrowRejected
+	if accept {
+		status = entity.EscrowAccepted
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	defer tx.Rollback(ctx)
+	res, err := scanPending(tx.QueryRow(ctx, queryResolve, id, userId, status))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.PendingTransfer{}, myErrors.NoPendingTransferErr
+		}
+		return entity.PendingTransfer{}, err
+	}
+	if accept {
+		_, err = tx.Exec(ctx, queryHistory, res.FromUserID, res.ToUserID, res.Amount, res.Message, res.Category)
+		if err != nil {
+			return entity.PendingTransfer{}, err
+		}
+		err = releaseEscrow(ctx, tx, res, res.ToUserID, entity.LedgerEscrowRelease)
+	} else {
+		err = releaseEscrow(ctx, tx, res, res.FromUserID, entity.LedgerEscrowReturn)
+	}
+	if err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.PendingTransfer{}, err
+	}
+	return res, nil
+}
+
+// ReturnExpired возвращает отправителям монеты переводов, не принятых до expires_at,
+// а также переводов, получатель которых удален и принять их уже некому.
+// Строки, которые обрабатывает другой экземпляр сервиса, пропускаются (skip locked).
+// Если удален и отправитель, монеты уходят со счета escrow на его осиротевший счет
+// в журнале, как и остальной баланс удаленного пользователя.
+func (r *Escrow) ReturnExpired(ctx context.Context, limit int) (int, error) {
+	query := `update pending_transfer set status='returned', resolved_at=NOW()
+		where id in (
+			select id from pending_transfer
+			where status='held' and (expires_at<=NOW() or to_user is null)
+			order by expires_at
+			limit $1
+			for update skip locked
+		)
+		returning id, coalesce(from_user, 0), amount;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+	expired := []entity.PendingTransfer{}
+	for rows.Next() {
+		var p entity.PendingTransfer
+		if err := rows.Scan(&p.ID, &p.FromUserID, &p.Amount); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		expired = append(expired, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	// отправители блокируются по возрастанию id, как и при обычных переводах
+	slices.SortStableFunc(expired, func(a, b entity.PendingTransfer) int {
+		return cmp.Compare(a.FromUserID, b.FromUserID)
+	})
+	for _, p := range expired {
+		if err := releaseEscrow(ctx, tx, p, p.FromUserID, entity.LedgerEscrowReturn); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// releaseEscrow зачисляет удержанную сумму пользователю to и проводит ее со счета escrow.
+// Нулевой to означает удаленного пользователя: кэш баланса обновлять некому, остается только проводка.
+func releaseEscrow(ctx context.Context, tx pgx5.Tx, p entity.PendingTransfer, to uint32, kind string) error {
+	queryCredit := `update "user" set coins=coins+$1 where id=$2;`
+	if to != 0 {
+		_, err := tx.Exec(ctx, queryCredit, p.Amount, to)
+		if err != nil {
+			return err
+		}
+	}
+	return postLedger(ctx, tx, entity.LedgerTransaction{
+		Kind:        kind,
+		ReferenceID: &p.ID,
+		Entries: []entity.LedgerEntry{
+			entity.SystemEntry(entity.AccountEscrow, -int64(p.Amount)),
+			entity.UserEntry(to, int64(p.Amount)),
+		},
+	})
+}
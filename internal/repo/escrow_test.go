@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var pendingRows = []string{"id", "from_user", "from_name", "to_user", "to_name", "amount", "message", "category",
+	"status", "created_at", "expires_at", "resolved_at"}
+
+func TestEscrow_Hold(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewEscrow(mock, zap.NewNop())
+
+	queryDebit := `update "user" set coins=coins-\$1 where id=\$2 and coins>=\$1 returning coins;`
+	queryInsert := `insert into pending_transfer\(from_user, to_user, amount, message, category, expires_at\)`
+	createdAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(72 * time.Hour)
+	transfer := entity.PendingTransfer{FromUserID: 1, ToUserID: 2, Amount: 100, Message: "спасибо", ExpiresAt: expiresAt}
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want entity.PendingTransfer
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				id := uint32(5)
+				m.ExpectBegin()
+				m.ExpectQuery(queryDebit).WithArgs(uint32(100), uint32(1)).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(900)))
+				m.ExpectQuery(queryInsert).WithArgs(uint32(1), uint32(2), uint32(100), "спасибо", "", expiresAt).
+					WillReturnRows(pgxmock.NewRows(pendingRows).
+						AddRow(id, uint32(1), "sofia", uint32(2), "mary", uint32(100), "спасибо", "",
+							"held", createdAt, expiresAt, (*time.Time)(nil)))
+				expectLedger(m, entity.LedgerEscrowHold, &id,
+					entity.UserEntry(1, -100),
+					entity.SystemEntry(entity.AccountEscrow, 100)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			want: entity.PendingTransfer{
+				ID: 5, FromUserID: 1, FromUser: "sofia", ToUserID: 2, ToUser: "mary", Amount: 100, Message: "спасибо",
+				Status: entity.EscrowHeld, CreatedAt: createdAt, ExpiresAt: expiresAt,
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, not enough coins",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryDebit).WithArgs(uint32(100), uint32(1)).WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: entity.PendingTransfer{},
+			err:  myErrors.NotEnoughCoinErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.Hold(context.Background(), transfer)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEscrow_Resolve(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewEscrow(mock, zap.NewNop())
+
+	queryResolve := `update pending_transfer set status=\$3, resolved_at=NOW\(\) where id=\$1 and to_user=\$2 and status='held' and expires_at>NOW\(\)`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	queryHistory := `insert into coin_history\(from_user, to_user, amount, message, category, created_at\)`
+	createdAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	resolvedAt := createdAt.Add(time.Hour)
+	id := uint32(5)
+	resolved := func(status string) *pgxmock.Rows {
+		return pgxmock.NewRows(pendingRows).
+			AddRow(id, uint32(1), "sofia", uint32(2), "mary", uint32(100), "спасибо", "thanks",
+				status, createdAt, createdAt.Add(72*time.Hour), &resolvedAt)
+	}
+
+	tests := []struct {
+		name   string
+		accept bool
+		mock   func(m pgxmock.PgxPoolIface)
+		status string
+		err    error
+	}{
+		{
+			name:   "Accept",
+			accept: true,
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryResolve).WithArgs(id, uint32(2), "accepted").WillReturnRows(resolved("accepted"))
+				m.ExpectExec(queryHistory).WithArgs(uint32(1), uint32(2), uint32(100), "спасибо", "thanks").
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectExec(queryCredit).WithArgs(uint32(100), uint32(2)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				expectLedger(m, entity.LedgerEscrowRelease, &id,
+					entity.SystemEntry(entity.AccountEscrow, -100),
+					entity.UserEntry(2, 100)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			status: entity.EscrowAccepted,
+			err:    nil,
+		},
+		{
+			name:   "Reject",
+			accept: false,
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryResolve).WithArgs(id, uint32(2), "rejected").WillReturnRows(resolved("rejected"))
+				m.ExpectExec(queryCredit).WithArgs(uint32(100), uint32(1)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				expectLedger(m, entity.LedgerEscrowReturn, &id,
+					entity.SystemEntry(entity.AccountEscrow, -100),
+					entity.UserEntry(1, 100)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			status: entity.EscrowRejected,
+			err:    nil,
+		},
+		{
+			name:   "Fail, not held or expired",
+			accept: true,
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryResolve).WithArgs(id, uint32(2), "accepted").WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			status: "",
+			err:    myErrors.NoPendingTransferErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.Resolve(context.Background(), 2, id, tt.accept)
+			assert.Equal(t, tt.status, res.Status)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEscrow_ReturnExpired(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewEscrow(mock, zap.NewNop())
+
+	query := `update pending_transfer set status='returned', resolved_at=NOW\(\) where id in \( select id from pending_transfer where status='held' and \(expires_at<=NOW\(\) or to_user is null\)`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).WithArgs(10).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "from_user", "amount"}).
+			AddRow(uint32(7), uint32(3), uint32(20)).
+			AddRow(uint32(5), uint32(1), uint32(100)).
+			AddRow(uint32(9), uint32(0), uint32(15)))
+	// отправители обрабатываются по возрастанию id; удаленному отправителю
+	// остается только проводка на его счет в журнале
+	for _, p := range []entity.PendingTransfer{{ID: 9, FromUserID: 0, Amount: 15}, {ID: 5, FromUserID: 1, Amount: 100}, {ID: 7, FromUserID: 3, Amount: 20}} {
+		id := p.ID
+		if p.FromUserID != 0 {
+			mock.ExpectExec(queryCredit).WithArgs(p.Amount, p.FromUserID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		}
+		expectLedger(mock, entity.LedgerEscrowReturn, &id,
+			entity.SystemEntry(entity.AccountEscrow, -int64(p.Amount)),
+			entity.UserEntry(p.FromUserID, int64(p.Amount))).
+			WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	}
+	mock.ExpectCommit()
+
+	returned, err := repo.ReturnExpired(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, returned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+//go:generate mockgen -source=idempotency.go -destination=mock/idempotency_mock.go -package=mock
+type IdempotencyInterface interface {
+	Lock(ctx context.Context, record entity.IdempotencyRecord, expiresAt time.Time) (*entity.IdempotencyRecord, error)
+	Save(ctx context.Context, record entity.IdempotencyRecord) error
+	Delete(ctx context.Context, key string, userId uint32) error
+}
+
+type Idempotency struct {
+	db DBInterface
+}
+
+func NewIdempotency(db DBInterface) IdempotencyInterface {
+	return &Idempotency{db: db}
+}
+
+// Lock резервирует ключ за пользователем. Если ключ свободен или его срок истек,
+// возвращает nil, иначе возвращает уже сохраненную запись.
+func (i *Idempotency) Lock(ctx context.Context, record entity.IdempotencyRecord, expiresAt time.Time) (*entity.IdempotencyRecord, error) {
+	queryInsert := `insert into idempotency_key(key, user_id, request_hash, expires_at) values ($1, $2, $3, $4)
+				on conflict (key, user_id) do update
+				set request_hash=excluded.request_hash, status_code=NULL, response=NULL, created_at=NOW(), expires_at=excluded.expires_at
+				where idempotency_key.expires_at <= NOW()
+				returning key;`
+	querySelect := `select request_hash, coalesce(status_code, 0), coalesce(response, '') from idempotency_key where key=$1 and user_id=$2;`
+	var key string
+	err := i.db.QueryRow(ctx, queryInsert, record.Key, record.UserID, record.RequestHash, expiresAt).Scan(&key)
+	if err == nil {
+		return nil, nil
+	}
+	if err.Error() != pgx.ErrNoRows.Error() {
+		return nil, err
+	}
+	res := entity.IdempotencyRecord{Key: record.Key, UserID: record.UserID}
+	err = i.db.QueryRow(ctx, querySelect, record.Key, record.UserID).Scan(&res.RequestHash, &res.StatusCode, &res.Response)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			// запись успели удалить между запросами, считаем что она еще обрабатывается
+			return &entity.IdempotencyRecord{Key: record.Key, UserID: record.UserID, RequestHash: record.RequestHash}, nil
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (i *Idempotency) Save(ctx context.Context, record entity.IdempotencyRecord) error {
+	query := `update idempotency_key set status_code=$1, response=$2 where key=$3 and user_id=$4;`
+	_, err := i.db.Exec(ctx, query, record.StatusCode, record.Response, record.Key, record.UserID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (i *Idempotency) Delete(ctx context.Context, key string, userId uint32) error {
+	query := `delete from idempotency_key where key=$1 and user_id=$2;`
+	_, err := i.db.Exec(ctx, query, key, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency_Lock(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewIdempotency(mock)
+
+	queryInsert := `insert into idempotency_key\(key, user_id, request_hash, expires_at\) values \(\$1, \$2, \$3, \$4\)`
+	querySelect := `select request_hash, coalesce\(status_code, 0\), coalesce\(response, ''\) from idempotency_key where key=\$1 and user_id=\$2;`
+	record := entity.IdempotencyRecord{Key: "key", UserID: 1, RequestHash: "hash"}
+	expiresAt := time.Now().Add(time.Hour)
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want *entity.IdempotencyRecord
+		err  error
+	}{
+		{
+			name: "Success, key is free",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(queryInsert).WithArgs(record.Key, record.UserID, record.RequestHash, expiresAt).
+					WillReturnRows(pgxmock.NewRows([]string{"key"}).AddRow(record.Key))
+			},
+			want: nil,
+			err:  nil,
+		},
+		{
+			name: "Success, key is already used",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(queryInsert).WithArgs(record.Key, record.UserID, record.RequestHash, expiresAt).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectQuery(querySelect).WithArgs(record.Key, record.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"request_hash", "status_code", "response"}).
+						AddRow("hash", 200, []byte(`"ok"`)))
+			},
+			want: &entity.IdempotencyRecord{Key: "key", UserID: 1, RequestHash: "hash", StatusCode: 200, Response: []byte(`"ok"`)},
+			err:  nil,
+		},
+		{
+			name: "Fail, db error on insert",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(queryInsert).WithArgs(record.Key, record.UserID, record.RequestHash, expiresAt).
+					WillReturnError(ErrDB)
+			},
+			want: nil,
+			err:  ErrDB,
+		},
+		{
+			name: "Fail, db error on select",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(queryInsert).WithArgs(record.Key, record.UserID, record.RequestHash, expiresAt).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectQuery(querySelect).WithArgs(record.Key, record.UserID).
+					WillReturnError(ErrDB)
+			},
+			want: nil,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.Lock(context.Background(), record, expiresAt)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestIdempotency_Save(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewIdempotency(mock)
+
+	query := `update idempotency_key set status_code=\$1, response=\$2 where key=\$3 and user_id=\$4;`
+	record := entity.IdempotencyRecord{Key: "key", UserID: 1, StatusCode: 200, Response: []byte(`"ok"`)}
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectExec(query).WithArgs(record.StatusCode, record.Response, record.Key, record.UserID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			},
+			err: nil,
+		},
+		{
+			name: "Fail",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectExec(query).WithArgs(record.StatusCode, record.Response, record.Key, record.UserID).
+					WillReturnError(ErrDB)
+			},
+			err: ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			err := repo.Save(context.Background(), record)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
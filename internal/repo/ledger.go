@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrUnbalancedLedger = errors.New("ledger transaction is not balanced")
+
+// postLedger записывает проводку со всеми ногами одним запросом внутри переданной транзакции.
+// Сбалансированность дополнительно проверяет отложенный триггер в базе.
+func postLedger(ctx context.Context, tx pgx.Tx, trans entity.LedgerTransaction) error {
+	query := `with t as (
+				insert into ledger_transaction(kind, reference_id, created_at) values ($1, $2, NOW()) returning id
+			)
+			insert into ledger_entry(transaction_id, account, user_id, amount)
+			select t.id, e.account, nullif(e.user_id, 0), e.amount
+			from t, unnest($3::text[], $4::bigint[], $5::bigint[]) as e(account, user_id, amount);`
+	if !trans.Balanced() {
+		return ErrUnbalancedLedger
+	}
+	accounts := make([]string, 0, len(trans.Entries))
+	users := make([]int64, 0, len(trans.Entries))
+	amounts := make([]int64, 0, len(trans.Entries))
+	for _, e := range trans.Entries {
+		accounts = append(accounts, e.Account)
+		users = append(users, int64(e.UserID))
+		amounts = append(amounts, e.Amount)
+	}
+	_, err := tx.Exec(ctx, query, trans.Kind, trans.ReferenceID, accounts, users, amounts)
+	return err
+}
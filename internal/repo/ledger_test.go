@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const queryLedger = `with t as \(
+	insert into ledger_transaction\(kind, reference_id, created_at\) values \(\$1, \$2, NOW\(\)\) returning id
+\)`
+
+// expectLedger ожидает запись проводки с указанными ногами
+func expectLedger(m pgxmock.PgxPoolIface, kind string, referenceId *uint32, entries ...entity.LedgerEntry) *pgxmock.ExpectedExec {
+	accounts := []string{}
+	users := []int64{}
+	amounts := []int64{}
+	for _, e := range entries {
+		accounts = append(accounts, e.Account)
+		users = append(users, int64(e.UserID))
+		amounts = append(amounts, e.Amount)
+	}
+	return m.ExpectExec(queryLedger).WithArgs(kind, referenceId, accounts, users, amounts)
+}
+
+func TestPostLedger(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	referenceId := uint32(5)
+
+	tests := []struct {
+		name  string
+		trans entity.LedgerTransaction
+		mock  func(m pgxmock.PgxPoolIface, trans entity.LedgerTransaction)
+		err   error
+	}{
+		{
+			name: "Success",
+			trans: entity.LedgerTransaction{
+				Kind:        entity.LedgerTransfer,
+				ReferenceID: &referenceId,
+				Entries:     []entity.LedgerEntry{entity.UserEntry(1, -100), entity.UserEntry(2, 100)},
+			},
+			mock: func(m pgxmock.PgxPoolIface, trans entity.LedgerTransaction) {
+				expectLedger(m, trans.Kind, trans.ReferenceID, trans.Entries...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, not balanced",
+			trans: entity.LedgerTransaction{
+				Kind:    entity.LedgerAdjustment,
+				Entries: []entity.LedgerEntry{entity.UserEntry(1, 100), entity.SystemEntry(entity.AccountIssuance, -90)},
+			},
+			mock: func(m pgxmock.PgxPoolIface, trans entity.LedgerTransaction) {},
+			err:  ErrUnbalancedLedger,
+		},
+		{
+			name: "Fail, single entry",
+			trans: entity.LedgerTransaction{
+				Kind:    entity.LedgerAdjustment,
+				Entries: []entity.LedgerEntry{entity.UserEntry(1, 0)},
+			},
+			mock: func(m pgxmock.PgxPoolIface, trans entity.LedgerTransaction) {},
+			err:  ErrUnbalancedLedger,
+		},
+		{
+			name: "Fail, db error",
+			trans: entity.LedgerTransaction{
+				Kind:    entity.LedgerInitialGrant,
+				Entries: []entity.LedgerEntry{entity.SystemEntry(entity.AccountIssuance, -1000), entity.UserEntry(1, 1000)},
+			},
+			mock: func(m pgxmock.PgxPoolIface, trans entity.LedgerTransaction) {
+				expectLedger(m, trans.Kind, trans.ReferenceID, trans.Entries...).
+					WillReturnError(ErrDB)
+			},
+			err: ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tx, err := mock.Begin(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.mock(mock, tt.trans)
+			err = postLedger(context.Background(), tx, tt.trans)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
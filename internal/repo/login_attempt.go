@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"time"
+)
+
+//go:generate mockgen -source=login_attempt.go -destination=mock/login_attempt_mock.go -package=mock
+type LoginAttemptInterface interface {
+	Get(ctx context.Context, keys []string) ([]entity.LoginAttempt, error)
+	RegisterFailure(ctx context.Context, key string, windowStart time.Time) (entity.LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type LoginAttempt struct {
+	db DBInterface
+}
+
+func NewLoginAttempt(db DBInterface) LoginAttemptInterface {
+	return &LoginAttempt{db: db}
+}
+
+func (l *LoginAttempt) Get(ctx context.Context, keys []string) ([]entity.LoginAttempt, error) {
+	query := `select key, failures, last_failure_at, locked_until from login_attempt where key = any($1);`
+	res := []entity.LoginAttempt{}
+	rows, err := l.db.Query(ctx, query, keys)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a entity.LoginAttempt
+		err := rows.Scan(&a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil)
+		if err != nil {
+			return []entity.LoginAttempt{}, err
+		}
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// RegisterFailure увеличивает счетчик ошибок. Если предыдущая ошибка была раньше
+// windowStart, счет начинается заново.
+func (l *LoginAttempt) RegisterFailure(ctx context.Context, key string, windowStart time.Time) (entity.LoginAttempt, error) {
+	query := `insert into login_attempt(key, failures, last_failure_at) values ($1, 1, NOW())
+				on conflict (key) do update
+				set failures = case when login_attempt.last_failure_at < $2 then 1 else login_attempt.failures + 1 end,
+					last_failure_at = NOW()
+				returning key, failures, last_failure_at, locked_until;`
+	var res entity.LoginAttempt
+	err := l.db.QueryRow(ctx, query, key, windowStart).Scan(&res.Key, &res.Failures, &res.LastFailureAt, &res.LockedUntil)
+	if err != nil {
+		return entity.LoginAttempt{}, err
+	}
+	return res, nil
+}
+
+func (l *LoginAttempt) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `update login_attempt set locked_until=$2 where key=$1;`
+	_, err := l.db.Exec(ctx, query, key, until)
+	return err
+}
+
+func (l *LoginAttempt) Reset(ctx context.Context, key string) error {
+	query := `delete from login_attempt where key=$1;`
+	_, err := l.db.Exec(ctx, query, key)
+	return err
+}
@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttempt_RegisterFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewLoginAttempt(mock)
+
+	query := `insert into login_attempt\(key, failures, last_failure_at\) values \(\$1, 1, NOW\(\)\)`
+	key := "user:mary"
+	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	windowStart := now.Add(-15 * time.Minute)
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want entity.LoginAttempt
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(key, windowStart).
+					WillReturnRows(pgxmock.NewRows([]string{"key", "failures", "last_failure_at", "locked_until"}).
+						AddRow(key, 2, now, nil))
+			},
+			want: entity.LoginAttempt{Key: key, Failures: 2, LastFailureAt: now},
+			err:  nil,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(key, windowStart).
+					WillReturnError(ErrDB)
+			},
+			want: entity.LoginAttempt{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.RegisterFailure(context.Background(), key, windowStart)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLoginAttempt_Get(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewLoginAttempt(mock)
+
+	query := `select key, failures, last_failure_at, locked_until from login_attempt where key = any\(\$1\);`
+	keys := []string{"user:mary", "ip:10.0.0.1"}
+	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(15 * time.Minute)
+
+	mock.ExpectQuery(query).WithArgs(keys).
+		WillReturnRows(pgxmock.NewRows([]string{"key", "failures", "last_failure_at", "locked_until"}).
+			AddRow("user:mary", 5, now, &until))
+	res, err := repo.Get(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.LoginAttempt{{Key: "user:mary", Failures: 5, LastFailureAt: now, LockedUntil: &until}}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,16 +2,27 @@ package repo
 
 import (
 	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"cmp"
 	"context"
+	"slices"
 
 	"github.com/jackc/pgx"
+	pgx5 "github.com/jackc/pgx/v5"
 )
 
 //go:generate mockgen -source=merch.go -destination=mock/merch_mock.go -package=mock
 type MerchInterface interface {
-	Buy(ctx context.Context, userId uint32, merchId uint32, cost uint32) error
+	Buy(ctx context.Context, userId uint32, merchId uint32) error
 	GetByName(ctx context.Context, name string) (*entity.Merch, error)
 	GetInventoryHistory(ctx context.Context, id uint32) ([]entity.Inventory, error)
+	GetAll(ctx context.Context) ([]entity.Merch, error)
+	ListMerch(ctx context.Context) ([]entity.Merch, error)
+	CreateMerch(ctx context.Context, merch entity.Merch) (entity.Merch, error)
+	UpdateMerch(ctx context.Context, merch entity.Merch) (*entity.Merch, error)
+	ArchiveMerch(ctx context.Context, id uint32) (*entity.Merch, error)
+	GetByNames(ctx context.Context, names []string) ([]entity.Merch, error)
+	CreateOrder(ctx context.Context, userId uint32, order entity.Order) (entity.Order, error)
 }
 
 type Merch struct {
@@ -22,22 +33,83 @@ func NewMerch(db DBInterface) MerchInterface {
 	return &Merch{db: db}
 }
 
-func (m *Merch) Buy(ctx context.Context, userId uint32, merchId uint32, cost uint32) error {
-	queryInsert := `insert into inventory(merch_id, user_id, created_at) values ($1, $2, NOW())`
+// Buy списывает цену товара, прочитанную в той же транзакции, поэтому параллельное
+// изменение цены или архивация товара не дают купить его по старой цене
+func (m *Merch) Buy(ctx context.Context, userId uint32, merchId uint32) error {
+	queryLimited := `select stock is not null from merch where id=$1 and archived_at is null;`
+	queryStock := `update merch set stock=stock-1 where id=$1 and archived_at is null and stock>0 returning cost;`
+	// разделяемая блокировка не мешает другим покупкам, но ждет изменения товара администратором
+	queryCost := `select cost from merch where id=$1 and archived_at is null for share;`
+	queryLock := `select coins from "user" where id=$1 for update;`
 	queryUpdate := `update "user" set coins=coins-$1 where id=$2;`
+	queryInsert := `insert into inventory(merch_id, user_id, cost, created_at) values ($1, $2, $3, NOW()) returning id;`
 	tx, err := m.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
-	_, err = tx.Exec(ctx, queryInsert, merchId, userId)
+	// строка товара без ограничения остатка не блокируется,
+	// чтобы покупки популярного товара не выстраивались в очередь
+	var limited bool
+	err = tx.QueryRow(ctx, queryLimited, merchId).Scan(&limited)
 	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return myErrors.NoMerchErr
+		}
 		return err
 	}
+	var cost uint32
+	if limited {
+		// остаток уменьшается в той же транзакции, что и списание монет:
+		// если денег не хватит, откат вернет товар на склад
+		err = tx.QueryRow(ctx, queryStock, merchId).Scan(&cost)
+		if err != nil {
+			if err.Error() == pgx.ErrNoRows.Error() {
+				return myErrors.OutOfStockErr
+			}
+			return err
+		}
+	} else {
+		err = tx.QueryRow(ctx, queryCost, merchId).Scan(&cost)
+		if err != nil {
+			if err.Error() == pgx.ErrNoRows.Error() {
+				return myErrors.NoMerchErr
+			}
+			return err
+		}
+	}
+	// баланс проверяется под блокировкой строки покупателя
+	var balance uint32
+	err = tx.QueryRow(ctx, queryLock, userId).Scan(&balance)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return myErrors.NoUserErr
+		}
+		return err
+	}
+	if balance < cost {
+		return myErrors.NotEnoughCoinErr
+	}
 	_, err = tx.Exec(ctx, queryUpdate, cost, userId)
 	if err != nil {
 		return err
 	}
+	var inventoryId uint32
+	err = tx.QueryRow(ctx, queryInsert, merchId, userId, cost).Scan(&inventoryId)
+	if err != nil {
+		return err
+	}
+	err = postLedger(ctx, tx, entity.LedgerTransaction{
+		Kind:        entity.LedgerPurchase,
+		ReferenceID: &inventoryId,
+		Entries: []entity.LedgerEntry{
+			entity.UserEntry(userId, -int64(cost)),
+			entity.SystemEntry(entity.AccountShop, int64(cost)),
+		},
+	})
+	if err != nil {
+		return err
+	}
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -45,7 +117,7 @@ func (m *Merch) Buy(ctx context.Context, userId uint32, merchId uint32, cost uin
 }
 
 func (m *Merch) GetByName(ctx context.Context, name string) (*entity.Merch, error) {
-	query := `select id, name, cost from merch where name=$1`
+	query := `select id, name, cost from merch where name=$1 and archived_at is null`
 	var res entity.Merch
 	err := m.db.QueryRow(ctx, query, name).Scan(&res.ID, &res.Name, &res.Cost)
 	if err != nil {
@@ -60,7 +132,7 @@ func (m *Merch) GetByName(ctx context.Context, name string) (*entity.Merch, erro
 func (m *Merch) GetInventoryHistory(ctx context.Context, id uint32) ([]entity.Inventory, error) {
 	query := `select m.name, count(i.merch_id) as quantity from inventory as i
 				JOIN merch as m ON i.merch_id=m.id 
-				WHERE i.user_id=$1
+				WHERE i.user_id=$1 and i.returned_at is null
 				GROUP BY m.name;`
 	res := []entity.Inventory{}
 	rows, err := m.db.Query(ctx, query, id)
@@ -77,3 +149,216 @@ func (m *Merch) GetInventoryHistory(ctx context.Context, id uint32) ([]entity.In
 	}
 	return res, nil
 }
+
+// GetAll возвращает весь каталог, включая снятые с продажи товары
+func (m *Merch) GetAll(ctx context.Context) ([]entity.Merch, error) {
+	query := `select id, name, cost, stock, archived_at from merch order by id;`
+	res := []entity.Merch{}
+	rows, err := m.db.Query(ctx, query)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var merch entity.Merch
+		err := rows.Scan(&merch.ID, &merch.Name, &merch.Cost, &merch.Stock, &merch.ArchivedAt)
+		if err != nil {
+			return []entity.Merch{}, err
+		}
+		res = append(res, merch)
+	}
+	return res, nil
+}
+
+// ListMerch возвращает товары, доступные для покупки
+func (m *Merch) ListMerch(ctx context.Context) ([]entity.Merch, error) {
+	query := `select id, name, cost, stock from merch where archived_at is null order by id;`
+	res := []entity.Merch{}
+	rows, err := m.db.Query(ctx, query)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var merch entity.Merch
+		err := rows.Scan(&merch.ID, &merch.Name, &merch.Cost, &merch.Stock)
+		if err != nil {
+			return []entity.Merch{}, err
+		}
+		res = append(res, merch)
+	}
+	return res, nil
+}
+
+func (m *Merch) CreateMerch(ctx context.Context, merch entity.Merch) (entity.Merch, error) {
+	query := `insert into merch(name, cost, stock) values ($1, $2, $3) returning id, name, cost, stock;`
+	var res entity.Merch
+	err := m.db.QueryRow(ctx, query, merch.Name, merch.Cost, merch.Stock).Scan(&res.ID, &res.Name, &res.Cost, &res.Stock)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return entity.Merch{}, myErrors.NotUnique
+		}
+		return entity.Merch{}, err
+	}
+	return res, nil
+}
+
+// UpdateMerch возвращает nil, если товара с таким id нет
+func (m *Merch) UpdateMerch(ctx context.Context, merch entity.Merch) (*entity.Merch, error) {
+	query := `update merch set name=$1, cost=$2, stock=$3 where id=$4 returning id, name, cost, stock, archived_at;`
+	var res entity.Merch
+	err := m.db.QueryRow(ctx, query, merch.Name, merch.Cost, merch.Stock, merch.ID).Scan(&res.ID, &res.Name, &res.Cost, &res.Stock, &res.ArchivedAt)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return nil, nil
+		}
+		if isUniqueViolation(err) {
+			return nil, myErrors.NotUnique
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ArchiveMerch снимает товар с продажи, не удаляя его: записи inventory продолжают
+// ссылаться на архивный товар. Повторный вызов не меняет дату архивации.
+// Возвращает nil, если товара с таким id нет.
+func (m *Merch) ArchiveMerch(ctx context.Context, id uint32) (*entity.Merch, error) {
+	query := `update merch set archived_at=coalesce(archived_at, NOW()) where id=$1 returning id, name, cost, stock, archived_at;`
+	var res entity.Merch
+	err := m.db.QueryRow(ctx, query, id).Scan(&res.ID, &res.Name, &res.Cost, &res.Stock, &res.ArchivedAt)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetByNames возвращает доступные для покупки товары из списка названий
+func (m *Merch) GetByNames(ctx context.Context, names []string) ([]entity.Merch, error) {
+	query := `select id, name, cost, stock from merch where name = any($1) and archived_at is null order by id;`
+	res := []entity.Merch{}
+	rows, err := m.db.Query(ctx, query, names)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var merch entity.Merch
+		err := rows.Scan(&merch.ID, &merch.Name, &merch.Cost, &merch.Stock)
+		if err != nil {
+			return []entity.Merch{}, err
+		}
+		res = append(res, merch)
+	}
+	return res, nil
+}
+
+// CreateOrder списывает остатки и монеты за все строки заказа в одной транзакции
+func (m *Merch) CreateOrder(ctx context.Context, userId uint32, order entity.Order) (entity.Order, error) {
+	queryStock := `update merch set stock=stock-$2 where id=$1 and stock>=$2 returning id;`
+	queryLock := `select coins from "user" where id=$1 for update;`
+	queryUpdate := `update "user" set coins=coins-$1 where id=$2;`
+	queryOrder := `insert into orders(user_id, total, created_at) values ($1, $2, NOW()) returning id, created_at;`
+	queryLine := `insert into order_lines(order_id, merch_id, quantity, cost) values ($1, $2, $3, $4);`
+	queryInsert := `insert into inventory(merch_id, user_id, order_id, cost, created_at)
+				select $1, $2, $3, $4, NOW() from generate_series(1, $5);`
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	defer tx.Rollback(ctx)
+	// строки товаров блокируются в порядке id, чтобы встречные заказы
+	// не взаимоблокировали друг друга
+	lines := slices.Clone(order.Lines)
+	slices.SortFunc(lines, func(a, b entity.OrderItem) int {
+		return cmp.Compare(a.MerchID, b.MerchID)
+	})
+	ids := make([]uint32, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.MerchID)
+	}
+	// как и в Buy, блокируются только строки товаров с ограниченным остатком
+	limited, err := m.limitedStock(ctx, tx, ids)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	for _, line := range lines {
+		if !limited[line.MerchID] {
+			continue
+		}
+		var id uint32
+		err = tx.QueryRow(ctx, queryStock, line.MerchID, line.Quantity).Scan(&id)
+		if err != nil {
+			if err.Error() == pgx.ErrNoRows.Error() {
+				return entity.Order{}, myErrors.OutOfStockErr
+			}
+			return entity.Order{}, err
+		}
+	}
+	var balance uint32
+	err = tx.QueryRow(ctx, queryLock, userId).Scan(&balance)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.Order{}, myErrors.NoUserErr
+		}
+		return entity.Order{}, err
+	}
+	if balance < order.Total {
+		return entity.Order{}, myErrors.NotEnoughCoinErr
+	}
+	_, err = tx.Exec(ctx, queryUpdate, order.Total, userId)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	err = tx.QueryRow(ctx, queryOrder, userId, order.Total).Scan(&order.ID, &order.CreatedAt)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	for _, line := range order.Lines {
+		_, err = tx.Exec(ctx, queryLine, order.ID, line.MerchID, line.Quantity, line.Cost)
+		if err != nil {
+			return entity.Order{}, err
+		}
+		_, err = tx.Exec(ctx, queryInsert, line.MerchID, userId, order.ID, line.Cost, line.Quantity)
+		if err != nil {
+			return entity.Order{}, err
+		}
+	}
+	err = postLedger(ctx, tx, entity.LedgerTransaction{
+		Kind:        entity.LedgerPurchase,
+		ReferenceID: &order.ID,
+		Entries: []entity.LedgerEntry{
+			entity.UserEntry(userId, -int64(order.Total)),
+			entity.SystemEntry(entity.AccountShop, int64(order.Total)),
+		},
+	})
+	if err != nil {
+		return entity.Order{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.Order{}, err
+	}
+	return order, nil
+}
+
+// limitedStock возвращает множество товаров из списка, у которых задан остаток
+func (m *Merch) limitedStock(ctx context.Context, tx pgx5.Tx, ids []uint32) (map[uint32]bool, error) {
+	query := `select id from merch where id = any($1) and stock is not null;`
+	rows, err := tx.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make(map[uint32]bool, len(ids))
+	for rows.Next() {
+		var id uint32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res[id] = true
+	}
+	return res, rows.Err()
+}
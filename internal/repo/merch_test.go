@@ -2,10 +2,13 @@ package repo
 
 import (
 	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,7 +21,7 @@ func TestMerch_GetByName(t *testing.T) {
 	defer mock.Close()
 
 	repo := NewMerch(mock)
-	query := `select id, name, cost from merch where name=\$1`
+	query := `select id, name, cost from merch where name=\$1 and archived_at is null`
 
 	tests := []struct {
 		name      string
@@ -80,7 +83,7 @@ func TestMerch_GetInventoryHistory(t *testing.T) {
 	repo := NewMerch(mock)
 	query := `select m.name, count\(i.merch_id\) as quantity from inventory as i
 	JOIN merch as m ON i.merch_id=m.id
-	WHERE i.user_id=\$1
+	WHERE i.user_id=\$1 and i.returned_at is null
 	GROUP BY m.name;`
 	id := uint32(1)
 
@@ -124,3 +127,504 @@ func TestMerch_GetInventoryHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestMerch_Buy(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewMerch(mock)
+
+	queryLimited := `select stock is not null from merch where id=\$1 and archived_at is null;`
+	queryStock := `update merch set stock=stock-1 where id=\$1 and archived_at is null and stock>0 returning cost;`
+	queryCost := `select cost from merch where id=\$1 and archived_at is null for share;`
+	queryLock := `select coins from "user" where id=\$1 for update;`
+	queryUpdate := `update "user" set coins=coins-\$1 where id=\$2;`
+	queryInsert := `insert into inventory\(merch_id, user_id, cost, created_at\) values \(\$1, \$2, \$3, NOW\(\)\) returning id;`
+	userId := uint32(1)
+	merchId := uint32(2)
+	cost := uint32(80)
+	inventoryId := uint32(10)
+	purchase := []entity.LedgerEntry{
+		entity.UserEntry(userId, -int64(cost)),
+		entity.SystemEntry(entity.AccountShop, int64(cost)),
+	}
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(false))
+				m.ExpectQuery(queryCost).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"cost"}).AddRow(cost))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(1000)))
+				m.ExpectExec(queryUpdate).WithArgs(cost, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryInsert).WithArgs(merchId, userId, cost).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(inventoryId))
+				expectLedger(m, entity.LedgerPurchase, &inventoryId, purchase...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name: "Success, limited stock",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(true))
+				m.ExpectQuery(queryStock).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"cost"}).AddRow(cost))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(1000)))
+				m.ExpectExec(queryUpdate).WithArgs(cost, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryInsert).WithArgs(merchId, userId, cost).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(inventoryId))
+				expectLedger(m, entity.LedgerPurchase, &inventoryId, purchase...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, not enough coins",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(true))
+				m.ExpectQuery(queryStock).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"cost"}).AddRow(cost))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(cost - 1))
+				m.ExpectRollback()
+			},
+			err: myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Fail, no user",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(true))
+				m.ExpectQuery(queryStock).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"cost"}).AddRow(cost))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NoUserErr,
+		},
+		{
+			name: "Fail, out of stock",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(true))
+				m.ExpectQuery(queryStock).WithArgs(merchId).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.OutOfStockErr,
+		},
+		{
+			name: "Fail, no merch",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NoMerchErr,
+		},
+		{
+			name: "Fail, archived before the lock",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(false))
+				m.ExpectQuery(queryCost).WithArgs(merchId).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			err: myErrors.NoMerchErr,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"limited"}).AddRow(true))
+				m.ExpectQuery(queryStock).WithArgs(merchId).
+					WillReturnRows(pgxmock.NewRows([]string{"cost"}).AddRow(cost))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(1000)))
+				m.ExpectExec(queryUpdate).WithArgs(cost, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryInsert).WithArgs(merchId, userId, cost).
+					WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			err: ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			err := repo.Buy(context.Background(), userId, merchId)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMerch_GetAll(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewMerch(mock)
+
+	query := `select id, name, cost, stock, archived_at from merch order by id;`
+	stock := uint32(3)
+	archivedAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want []entity.Merch
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WillReturnRows(
+					pgxmock.NewRows([]string{"id", "name", "cost", "stock", "archived_at"}).
+						AddRow(uint32(1), "pen", uint32(10), nil, nil).
+						AddRow(uint32(2), "cup", uint32(20), &stock, &archivedAt))
+			},
+			want: []entity.Merch{
+				{ID: 1, Name: "pen", Cost: 10},
+				{ID: 2, Name: "cup", Cost: 20, Stock: &stock, ArchivedAt: &archivedAt},
+			},
+			err: nil,
+		},
+		{
+			name: "Fail",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WillReturnError(ErrDB)
+			},
+			want: []entity.Merch{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.GetAll(context.Background())
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestMerch_CreateMerch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewMerch(mock)
+
+	query := `insert into merch\(name, cost, stock\) values \(\$1, \$2, \$3\) returning id, name, cost, stock;`
+	stock := uint32(100)
+	merch := entity.Merch{Name: "sticker", Cost: 5, Stock: &stock}
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want entity.Merch
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs("sticker", uint32(5), &stock).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "cost", "stock"}).AddRow(uint32(11), "sticker", uint32(5), &stock))
+			},
+			want: entity.Merch{ID: 11, Name: "sticker", Cost: 5, Stock: &stock},
+			err:  nil,
+		},
+		{
+			name: "Fail, merch already exists",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs("sticker", uint32(5), &stock).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+			},
+			want: entity.Merch{},
+			err:  myErrors.NotUnique,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.CreateMerch(context.Background(), merch)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestMerch_ArchiveMerch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewMerch(mock)
+
+	query := `update merch set archived_at=coalesce\(archived_at, NOW\(\)\) where id=\$1 returning id, name, cost, stock, archived_at;`
+	archivedAt := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want *entity.Merch
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(uint32(1)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "cost", "stock", "archived_at"}).
+						AddRow(uint32(1), "pen", uint32(10), nil, &archivedAt))
+			},
+			want: &entity.Merch{ID: 1, Name: "pen", Cost: 10, ArchivedAt: &archivedAt},
+			err:  nil,
+		},
+		{
+			name: "Success, no merch",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(uint32(1)).WillReturnError(pgx.ErrNoRows)
+			},
+			want: nil,
+			err:  nil,
+		},
+		{
+			name: "Fail",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(uint32(1)).WillReturnError(ErrDB)
+			},
+			want: nil,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.ArchiveMerch(context.Background(), 1)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestMerch_ListMerch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewMerch(mock)
+
+	query := `select id, name, cost, stock from merch where archived_at is null order by id;`
+	stock := uint32(0)
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want []entity.Merch
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WillReturnRows(
+					pgxmock.NewRows([]string{"id", "name", "cost", "stock"}).
+						AddRow(uint32(1), "pen", uint32(10), nil).
+						AddRow(uint32(2), "cup", uint32(20), &stock))
+			},
+			want: []entity.Merch{
+				{ID: 1, Name: "pen", Cost: 10},
+				{ID: 2, Name: "cup", Cost: 20, Stock: &stock},
+			},
+			err: nil,
+		},
+		{
+			name: "Fail",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WillReturnError(ErrDB)
+			},
+			want: []entity.Merch{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.ListMerch(context.Background())
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestMerch_CreateOrder(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewMerch(mock)
+
+	queryLimited := `select id from merch where id = any\(\$1\) and stock is not null;`
+	queryStock := `update merch set stock=stock-\$2 where id=\$1 and stock>=\$2 returning id;`
+	queryLock := `select coins from "user" where id=\$1 for update;`
+	queryUpdate := `update "user" set coins=coins-\$1 where id=\$2;`
+	queryOrder := `insert into orders\(user_id, total, created_at\) values \(\$1, \$2, NOW\(\)\) returning id, created_at;`
+	queryLine := `insert into order_lines\(order_id, merch_id, quantity, cost\) values \(\$1, \$2, \$3, \$4\);`
+	queryInsert := `insert into inventory\(merch_id, user_id, order_id, cost, created_at\)`
+	userId := uint32(1)
+	orderId := uint32(7)
+	createdAt := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	// строки намеренно не отсортированы по id товара
+	order := entity.Order{
+		Total: 70,
+		Lines: []entity.OrderItem{
+			{MerchID: 5, Item: "socks", Quantity: 3, Cost: 10},
+			{MerchID: 2, Item: "pen", Quantity: 2, Cost: 20},
+		},
+	}
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want entity.Order
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs([]uint32{2, 5}).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(5), uint32(3)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(1000)))
+				m.ExpectExec(queryUpdate).WithArgs(order.Total, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryOrder).WithArgs(userId, order.Total).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(orderId, createdAt))
+				m.ExpectExec(queryLine).WithArgs(orderId, uint32(5), uint32(3), uint32(10)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectExec(queryInsert).WithArgs(uint32(5), userId, orderId, uint32(10), uint32(3)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 3))
+				m.ExpectExec(queryLine).WithArgs(orderId, uint32(2), uint32(2), uint32(20)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectExec(queryInsert).WithArgs(uint32(2), userId, orderId, uint32(20), uint32(2)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				expectLedger(m, entity.LedgerPurchase, &orderId,
+					entity.UserEntry(userId, -int64(order.Total)),
+					entity.SystemEntry(entity.AccountShop, int64(order.Total))).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			want: entity.Order{ID: orderId, Total: order.Total, Lines: order.Lines, CreatedAt: createdAt},
+			err:  nil,
+		},
+		{
+			name: "Fail, out of stock",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs([]uint32{2, 5}).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)).AddRow(uint32(5)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(2), uint32(2)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(5), uint32(3)).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: entity.Order{},
+			err:  myErrors.OutOfStockErr,
+		},
+		{
+			name: "Fail, not enough coins",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs([]uint32{2, 5}).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)).AddRow(uint32(5)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(2), uint32(2)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(5), uint32(3)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(order.Total - 1))
+				m.ExpectRollback()
+			},
+			want: entity.Order{},
+			err:  myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Fail, no user",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs([]uint32{2, 5}).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)).AddRow(uint32(5)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(2), uint32(2)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(5), uint32(3)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: entity.Order{},
+			err:  myErrors.NoUserErr,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLimited).WithArgs([]uint32{2, 5}).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)).AddRow(uint32(5)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(2), uint32(2)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)))
+				m.ExpectQuery(queryStock).WithArgs(uint32(5), uint32(3)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectQuery(queryLock).WithArgs(userId).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(1000)))
+				m.ExpectExec(queryUpdate).WithArgs(order.Total, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryOrder).WithArgs(userId, order.Total).
+					WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			want: entity.Order{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.CreateOrder(context.Background(), userId, order)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
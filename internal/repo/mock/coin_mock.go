@@ -65,6 +65,36 @@ func (mr *MockCoinInterfaceMockRecorder) GetCoinHistory(ctx, id interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoinHistory", reflect.TypeOf((*MockCoinInterface)(nil).GetCoinHistory), ctx, id)
 }
 
+// GetCoinHistoryPage mocks base method.
+func (m *MockCoinInterface) GetCoinHistoryPage(ctx context.Context, filter entity.CoinHistoryFilter) ([]entity.HistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoinHistoryPage", ctx, filter)
+	ret0, _ := ret[0].([]entity.HistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoinHistoryPage indicates an expected call of GetCoinHistoryPage.
+func (mr *MockCoinInterfaceMockRecorder) GetCoinHistoryPage(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoinHistoryPage", reflect.TypeOf((*MockCoinInterface)(nil).GetCoinHistoryPage), ctx, filter)
+}
+
+// GetRefunds mocks base method.
+func (m *MockCoinInterface) GetRefunds(ctx context.Context, id uint32) ([]entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefunds", ctx, id)
+	ret0, _ := ret[0].([]entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefunds indicates an expected call of GetRefunds.
+func (mr *MockCoinInterfaceMockRecorder) GetRefunds(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefunds", reflect.TypeOf((*MockCoinInterface)(nil).GetRefunds), ctx, id)
+}
+
 // SendCoin mocks base method.
 func (m *MockCoinInterface) SendCoin(ctx context.Context, transaction entity.Transaction) error {
 	m.ctrl.T.Helper()
@@ -78,3 +108,17 @@ func (mr *MockCoinInterfaceMockRecorder) SendCoin(ctx, transaction interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCoin", reflect.TypeOf((*MockCoinInterface)(nil).SendCoin), ctx, transaction)
 }
+
+// SendCoinBatch mocks base method.
+func (m *MockCoinInterface) SendCoinBatch(ctx context.Context, transactions []entity.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCoinBatch", ctx, transactions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendCoinBatch indicates an expected call of SendCoinBatch.
+func (mr *MockCoinInterfaceMockRecorder) SendCoinBatch(ctx, transactions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCoinBatch", reflect.TypeOf((*MockCoinInterface)(nil).SendCoinBatch), ctx, transactions)
+}
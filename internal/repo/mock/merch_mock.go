@@ -35,18 +35,78 @@ func (m *MockMerchInterface) EXPECT() *MockMerchInterfaceMockRecorder {
 	return m.recorder
 }
 
+// ArchiveMerch mocks base method.
+func (m *MockMerchInterface) ArchiveMerch(ctx context.Context, id uint32) (*entity.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveMerch", ctx, id)
+	ret0, _ := ret[0].(*entity.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveMerch indicates an expected call of ArchiveMerch.
+func (mr *MockMerchInterfaceMockRecorder) ArchiveMerch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveMerch", reflect.TypeOf((*MockMerchInterface)(nil).ArchiveMerch), ctx, id)
+}
+
 // Buy mocks base method.
-func (m *MockMerchInterface) Buy(ctx context.Context, userId, merchId, cost uint32) error {
+func (m *MockMerchInterface) Buy(ctx context.Context, userId, merchId uint32) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Buy", ctx, userId, merchId, cost)
+	ret := m.ctrl.Call(m, "Buy", ctx, userId, merchId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Buy indicates an expected call of Buy.
-func (mr *MockMerchInterfaceMockRecorder) Buy(ctx, userId, merchId, cost interface{}) *gomock.Call {
+func (mr *MockMerchInterfaceMockRecorder) Buy(ctx, userId, merchId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buy", reflect.TypeOf((*MockMerchInterface)(nil).Buy), ctx, userId, merchId)
+}
+
+// CreateMerch mocks base method.
+func (m *MockMerchInterface) CreateMerch(ctx context.Context, merch entity.Merch) (entity.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMerch", ctx, merch)
+	ret0, _ := ret[0].(entity.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMerch indicates an expected call of CreateMerch.
+func (mr *MockMerchInterfaceMockRecorder) CreateMerch(ctx, merch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerch", reflect.TypeOf((*MockMerchInterface)(nil).CreateMerch), ctx, merch)
+}
+
+// CreateOrder mocks base method.
+func (m *MockMerchInterface) CreateOrder(ctx context.Context, userId uint32, order entity.Order) (entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, userId, order)
+	ret0, _ := ret[0].(entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockMerchInterfaceMockRecorder) CreateOrder(ctx, userId, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockMerchInterface)(nil).CreateOrder), ctx, userId, order)
+}
+
+// GetAll mocks base method.
+func (m *MockMerchInterface) GetAll(ctx context.Context) ([]entity.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]entity.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockMerchInterfaceMockRecorder) GetAll(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buy", reflect.TypeOf((*MockMerchInterface)(nil).Buy), ctx, userId, merchId, cost)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockMerchInterface)(nil).GetAll), ctx)
 }
 
 // GetByName mocks base method.
@@ -64,6 +124,21 @@ func (mr *MockMerchInterfaceMockRecorder) GetByName(ctx, name interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockMerchInterface)(nil).GetByName), ctx, name)
 }
 
+// GetByNames mocks base method.
+func (m *MockMerchInterface) GetByNames(ctx context.Context, names []string) ([]entity.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByNames", ctx, names)
+	ret0, _ := ret[0].([]entity.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByNames indicates an expected call of GetByNames.
+func (mr *MockMerchInterfaceMockRecorder) GetByNames(ctx, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNames", reflect.TypeOf((*MockMerchInterface)(nil).GetByNames), ctx, names)
+}
+
 // GetInventoryHistory mocks base method.
 func (m *MockMerchInterface) GetInventoryHistory(ctx context.Context, id uint32) ([]entity.Inventory, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +153,33 @@ func (mr *MockMerchInterfaceMockRecorder) GetInventoryHistory(ctx, id interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventoryHistory", reflect.TypeOf((*MockMerchInterface)(nil).GetInventoryHistory), ctx, id)
 }
+
+// ListMerch mocks base method.
+func (m *MockMerchInterface) ListMerch(ctx context.Context) ([]entity.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerch", ctx)
+	ret0, _ := ret[0].([]entity.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerch indicates an expected call of ListMerch.
+func (mr *MockMerchInterfaceMockRecorder) ListMerch(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerch", reflect.TypeOf((*MockMerchInterface)(nil).ListMerch), ctx)
+}
+
+// UpdateMerch mocks base method.
+func (m *MockMerchInterface) UpdateMerch(ctx context.Context, merch entity.Merch) (*entity.Merch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerch", ctx, merch)
+	ret0, _ := ret[0].(*entity.Merch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMerch indicates an expected call of UpdateMerch.
+func (mr *MockMerchInterfaceMockRecorder) UpdateMerch(ctx, merch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerch", reflect.TypeOf((*MockMerchInterface)(nil).UpdateMerch), ctx, merch)
+}
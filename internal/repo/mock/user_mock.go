@@ -35,6 +35,20 @@ func (m *MockUserInterface) EXPECT() *MockUserInterfaceMockRecorder {
 	return m.recorder
 }
 
+// CreatePasswordReset mocks base method.
+func (m *MockUserInterface) CreatePasswordReset(ctx context.Context, reset entity.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", ctx, reset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockUserInterfaceMockRecorder) CreatePasswordReset(ctx, reset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockUserInterface)(nil).CreatePasswordReset), ctx, reset)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserInterface) CreateUser(ctx context.Context, name, password string) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +64,21 @@ func (mr *MockUserInterfaceMockRecorder) CreateUser(ctx, name, password interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserInterface)(nil).CreateUser), ctx, name, password)
 }
 
+// GetByNames mocks base method.
+func (m *MockUserInterface) GetByNames(ctx context.Context, names []string) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByNames", ctx, names)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByNames indicates an expected call of GetByNames.
+func (mr *MockUserInterfaceMockRecorder) GetByNames(ctx, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNames", reflect.TypeOf((*MockUserInterface)(nil).GetByNames), ctx, names)
+}
+
 // GetPassword mocks base method.
 func (m *MockUserInterface) GetPassword(ctx context.Context, id uint32) (entity.Password, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +108,47 @@ func (mr *MockUserInterfaceMockRecorder) GetUser(ctx, name, id interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserInterface)(nil).GetUser), ctx, name, id)
 }
+
+// ResetPassword mocks base method.
+func (m *MockUserInterface) ResetPassword(ctx context.Context, tokenHash, password string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, tokenHash, password)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserInterfaceMockRecorder) ResetPassword(ctx, tokenHash, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserInterface)(nil).ResetPassword), ctx, tokenHash, password)
+}
+
+// SetPassword mocks base method.
+func (m *MockUserInterface) SetPassword(ctx context.Context, id uint32, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockUserInterfaceMockRecorder) SetPassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockUserInterface)(nil).SetPassword), ctx, id, password)
+}
+
+// SetRole mocks base method.
+func (m *MockUserInterface) SetRole(ctx context.Context, name, role string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", ctx, name, role)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockUserInterfaceMockRecorder) SetRole(ctx, name, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockUserInterface)(nil).SetRole), ctx, name, role)
+}
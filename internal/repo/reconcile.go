@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+
+	"github.com/jackc/pgx"
+)
+
+//go:generate mockgen -source=reconcile.go -destination=mock/reconcile_mock.go -package=mock
+type ReconcileInterface interface {
+	CheckBalances(ctx context.Context) ([]entity.BalanceCheck, error)
+	Adjust(ctx context.Context, check entity.BalanceCheck) (bool, error)
+}
+
+type Reconcile struct {
+	db DBInterface
+}
+
+func NewReconcile(db DBInterface) ReconcileInterface {
+	return &Reconcile{db: db}
+}
+
+// CheckBalances пересчитывает баланс каждого пользователя из стартового и периодических начислений
+// (без сгоревшей части), переводов и покупок. Для покупок, сохраненных до появления inventory.cost,
+// берется текущая цена мерча. Возвращенные покупки не учитываются, а монеты
+// переводов, ожидающих подтверждения получателя, вычитаются из баланса отправителя.
+// Рядом возвращается сумма по журналу, чтобы найти расхождения кэша "user".coins с ним.
+func (r *Reconcile) CheckBalances(ctx context.Context) ([]entity.BalanceCheck, error) {
+	query := `select u.id, u.name, u.coins, ($1::bigint
+				+ coalesce((select sum(h.amount) from coin_history as h where h.to_user=u.id), 0)
+				+ coalesce((select sum(g.amount - g.expired_amount) from allowance_grant as g where g.user_id=u.id), 0)
+				- coalesce((select sum(h.amount) from coin_history as h where h.from_user=u.id), 0)
+				- coalesce((select sum(case when i.cost > 0 then i.cost else coalesce(m.cost, 0) end)
+					from inventory as i left join merch as m on i.merch_id=m.id
+					where i.user_id=u.id and i.returned_at is null), 0)
+				- coalesce((select sum(p.amount) from pending_transfer as p where p.from_user=u.id and p.status='held'), 0))::bigint,
+				coalesce((select sum(e.amount) from ledger_entry as e where e.account='user' and e.user_id=u.id), 0)::bigint
+			from "user" as u
+			order by u.id;`
+	res := []entity.BalanceCheck{}
+	rows, err := r.db.Query(ctx, query, COINS)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var check entity.BalanceCheck
+		err := rows.Scan(&check.UserID, &check.Name, &check.Actual, &check.Expected, &check.Ledger)
+		if err != nil {
+			return []entity.BalanceCheck{}, err
+		}
+		res = append(res, check)
+	}
+	return res, nil
+}
+
+// Adjust выставляет пользователю пересчитанный баланс и доводит до него журнал
+// корректирующей проводкой. Если баланс изменился после проверки, корректировка
+// пропускается и возвращается false.
+func (r *Reconcile) Adjust(ctx context.Context, check entity.BalanceCheck) (bool, error) {
+	queryLock := `select coins from "user" where id=$1 for update;`
+	queryLedger := `select coalesce(sum(amount), 0)::bigint from ledger_entry where account='user' and user_id=$1;`
+	queryUpdate := `update "user" set coins=$1 where id=$2;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	var coins int64
+	err = tx.QueryRow(ctx, queryLock, check.UserID).Scan(&coins)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return false, nil
+		}
+		return false, err
+	}
+	if coins != check.Actual {
+		return false, nil
+	}
+	// все движения монет меняют "user".coins под этой же блокировкой,
+	// поэтому сумма журнала до коммита не изменится
+	var ledger int64
+	err = tx.QueryRow(ctx, queryLedger, check.UserID).Scan(&ledger)
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(ctx, queryUpdate, check.Expected, check.UserID)
+	if err != nil {
+		return false, err
+	}
+	if diff := check.Expected - ledger; diff != 0 {
+		err = postLedger(ctx, tx, entity.LedgerTransaction{
+			Kind: entity.LedgerAdjustment,
+			Entries: []entity.LedgerEntry{
+				entity.SystemEntry(entity.AccountIssuance, -diff),
+				entity.UserEntry(check.UserID, diff),
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
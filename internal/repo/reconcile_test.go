@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile_CheckBalances(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewReconcile(mock)
+	query := `select u.id, u.name, u.coins, \(\$1::bigint[\s\S]*from pending_transfer as p where p.from_user=u.id and p.status='held'[\s\S]*from ledger_entry as e where e.account='user' and e.user_id=u.id`
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want []entity.BalanceCheck
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(COINS).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins", "expected", "ledger"}).
+						AddRow(uint32(1), "sofia", int64(900), int64(900), int64(900)).
+						AddRow(uint32(2), "mary", int64(1100), int64(1000), int64(1100)))
+			},
+			want: []entity.BalanceCheck{
+				{UserID: 1, Name: "sofia", Actual: 900, Expected: 900, Ledger: 900},
+				{UserID: 2, Name: "mary", Actual: 1100, Expected: 1000, Ledger: 1100},
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs(COINS).
+					WillReturnError(ErrDB)
+			},
+			want: []entity.BalanceCheck{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.CheckBalances(context.Background())
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReconcile_Adjust(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewReconcile(mock)
+
+	queryLock := `select coins from "user" where id=\$1 for update;`
+	queryLedger := `select coalesce\(sum\(amount\), 0\)::bigint from ledger_entry where account='user' and user_id=\$1;`
+	queryUpdate := `update "user" set coins=\$1 where id=\$2;`
+	check := entity.BalanceCheck{UserID: 2, Name: "mary", Actual: 1100, Expected: 1000, Ledger: 1100}
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want bool
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(check.Actual))
+				m.ExpectQuery(queryLedger).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(check.Ledger))
+				m.ExpectExec(queryUpdate).WithArgs(check.Expected, check.UserID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				expectLedger(m, entity.LedgerAdjustment, nil,
+					entity.SystemEntry(entity.AccountIssuance, 100),
+					entity.UserEntry(check.UserID, -100)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			want: true,
+			err:  nil,
+		},
+		{
+			name: "Success, ledger already matches",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(check.Actual))
+				m.ExpectQuery(queryLedger).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(check.Expected))
+				m.ExpectExec(queryUpdate).WithArgs(check.Expected, check.UserID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectCommit()
+			},
+			want: true,
+			err:  nil,
+		},
+		{
+			name: "Balance changed after check",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(check.Actual - 10))
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  nil,
+		},
+		{
+			name: "User was deleted",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(check.UserID).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  nil,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(check.Actual))
+				m.ExpectQuery(queryLedger).WithArgs(check.UserID).
+					WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(check.Ledger))
+				m.ExpectExec(queryUpdate).WithArgs(check.Expected, check.UserID).
+					WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.Adjust(context.Background(), check)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+//go:generate mockgen -source=refund.go -destination=mock/refund_mock.go -package=mock
+type RefundInterface interface {
+	Return(ctx context.Context, userId uint32, inventoryId uint32, notBefore time.Time) (entity.Refund, error)
+	ListInventory(ctx context.Context, userId uint32) ([]entity.InventoryItem, error)
+}
+
+type Refund struct {
+	db DBInterface
+}
+
+func NewRefund(db DBInterface) RefundInterface {
+	return &Refund{db: db}
+}
+
+// Return возвращает купленный товар: убирает его из инвентаря, возвращает на склад
+// и зачисляет покупателю уплаченные монеты. Покупки, сделанные раньше notBefore,
+// вернуть нельзя. Для покупок, сохраненных до появления inventory.cost, сумма
+// берется из текущей цены товара, как и при сверке балансов.
+func (r *Refund) Return(ctx context.Context, userId uint32, inventoryId uint32, notBefore time.Time) (entity.Refund, error) {
+	queryLock := `select i.merch_id, case when i.cost > 0 then i.cost else coalesce(m.cost, 0) end, i.created_at, i.returned_at is not null
+				from inventory as i left join merch as m on i.merch_id=m.id
+				where i.id=$1 and i.user_id=$2 for update of i;`
+	queryReturn := `update inventory set returned_at=NOW() where id=$1;`
+	queryStock := `update merch set stock=stock+1 where id=$1 and stock is not null;`
+	queryCredit := `update "user" set coins=coins+$1 where id=$2;`
+	queryRefund := `insert into refund(inventory_id, user_id, amount, created_at) values ($1, $2, $3, NOW()) returning id, created_at;`
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	defer tx.Rollback(ctx)
+	// строка инвентаря блокируется, чтобы параллельные запросы не вернули товар дважды
+	var merchId *uint32
+	var createdAt time.Time
+	var returned bool
+	res := entity.Refund{InventoryID: inventoryId}
+	err = tx.QueryRow(ctx, queryLock, inventoryId, userId).Scan(&merchId, &res.Amount, &createdAt, &returned)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.Refund{}, myErrors.NoInventoryErr
+		}
+		return entity.Refund{}, err
+	}
+	if returned {
+		return entity.Refund{}, myErrors.AlreadyReturnedErr
+	}
+	if createdAt.Before(notBefore) {
+		return entity.Refund{}, myErrors.ReturnWindowExpiredErr
+	}
+	_, err = tx.Exec(ctx, queryReturn, inventoryId)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	// товар мог быть удален из каталога, тогда возвращать на склад нечего
+	if merchId != nil {
+		_, err = tx.Exec(ctx, queryStock, *merchId)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+	if res.Amount > 0 {
+		_, err = tx.Exec(ctx, queryCredit, res.Amount, userId)
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+	err = tx.QueryRow(ctx, queryRefund, inventoryId, userId, res.Amount).Scan(&res.ID, &res.CreatedAt)
+	if err != nil {
+		return entity.Refund{}, err
+	}
+	// старая покупка удаленного товара: цену узнать неоткуда, а нулевые проводки запрещены
+	if res.Amount > 0 {
+		err = postLedger(ctx, tx, entity.LedgerTransaction{
+			Kind:        entity.LedgerRefund,
+			ReferenceID: &res.ID,
+			Entries: []entity.LedgerEntry{
+				entity.SystemEntry(entity.AccountShop, -int64(res.Amount)),
+				entity.UserEntry(userId, int64(res.Amount)),
+			},
+		})
+		if err != nil {
+			return entity.Refund{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.Refund{}, err
+	}
+	return res, nil
+}
+
+// ListInventory возвращает покупки пользователя по одной, начиная с последних.
+// Цена считается так же, как при возврате.
+func (r *Refund) ListInventory(ctx context.Context, userId uint32) ([]entity.InventoryItem, error) {
+	query := `select i.id, coalesce(m.name, ''), case when i.cost > 0 then i.cost else coalesce(m.cost, 0) end, i.created_at, i.returned_at
+			from inventory as i left join merch as m on i.merch_id=m.id
+			where i.user_id=$1
+			order by i.id desc;`
+	res := []entity.InventoryItem{}
+	rows, err := r.db.Query(ctx, query, userId)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var i entity.InventoryItem
+		err := rows.Scan(&i.ID, &i.Type, &i.Cost, &i.BoughtAt, &i.ReturnedAt)
+		if err != nil {
+			return []entity.InventoryItem{}, err
+		}
+		res = append(res, i)
+	}
+	return res, nil
+}
@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefund_Return(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewRefund(mock)
+
+	queryLock := `select i.merch_id, case when i.cost > 0 then i.cost else coalesce\(m.cost, 0\) end, i.created_at, i.returned_at is not null
+				from inventory as i left join merch as m on i.merch_id=m.id
+				where i.id=\$1 and i.user_id=\$2 for update of i;`
+	queryReturn := `update inventory set returned_at=NOW\(\) where id=\$1;`
+	queryStock := `update merch set stock=stock\+1 where id=\$1 and stock is not null;`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	queryRefund := `insert into refund\(inventory_id, user_id, amount, created_at\) values \(\$1, \$2, \$3, NOW\(\)\) returning id, created_at;`
+	userId := uint32(1)
+	inventoryId := uint32(10)
+	merchId := uint32(2)
+	cost := uint32(80)
+	now := time.Date(2025, 2, 15, 12, 0, 0, 0, time.UTC)
+	notBefore := now.Add(-24 * time.Hour)
+	boughtAt := now.Add(-time.Hour)
+	refundId := uint32(3)
+	refund := []entity.LedgerEntry{
+		entity.SystemEntry(entity.AccountShop, -int64(cost)),
+		entity.UserEntry(userId, int64(cost)),
+	}
+	lockRows := func(merchId *uint32, createdAt time.Time, returned bool) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"merch_id", "cost", "created_at", "returned"}).
+			AddRow(merchId, cost, createdAt, returned)
+	}
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want entity.Refund
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnRows(lockRows(&merchId, boughtAt, false))
+				m.ExpectExec(queryReturn).WithArgs(inventoryId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec(queryStock).WithArgs(merchId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec(queryCredit).WithArgs(cost, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryRefund).WithArgs(inventoryId, userId, cost).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(refundId, now))
+				expectLedger(m, entity.LedgerRefund, &refundId, refund...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			want: entity.Refund{ID: refundId, InventoryID: inventoryId, Amount: cost, CreatedAt: now},
+			err:  nil,
+		},
+		{
+			name: "Success, merch was deleted",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnRows(lockRows(nil, boughtAt, false))
+				m.ExpectExec(queryReturn).WithArgs(inventoryId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectExec(queryCredit).WithArgs(cost, userId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryRefund).WithArgs(inventoryId, userId, cost).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(refundId, now))
+				expectLedger(m, entity.LedgerRefund, &refundId, refund...).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
+			},
+			want: entity.Refund{ID: refundId, InventoryID: inventoryId, Amount: cost, CreatedAt: now},
+			err:  nil,
+		},
+		{
+			name: "Success, nothing to refund",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnRows(pgxmock.NewRows([]string{"merch_id", "cost", "created_at", "returned"}).
+						AddRow(nil, uint32(0), boughtAt, false))
+				m.ExpectExec(queryReturn).WithArgs(inventoryId).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryRefund).WithArgs(inventoryId, userId, uint32(0)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(refundId, now))
+				m.ExpectCommit()
+			},
+			want: entity.Refund{ID: refundId, InventoryID: inventoryId, Amount: 0, CreatedAt: now},
+			err:  nil,
+		},
+		{
+			name: "Fail, no inventory",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: entity.Refund{},
+			err:  myErrors.NoInventoryErr,
+		},
+		{
+			name: "Fail, already returned",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnRows(lockRows(&merchId, boughtAt, true))
+				m.ExpectRollback()
+			},
+			want: entity.Refund{},
+			err:  myErrors.AlreadyReturnedErr,
+		},
+		{
+			name: "Fail, window expired",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnRows(lockRows(&merchId, notBefore.Add(-time.Second), false))
+				m.ExpectRollback()
+			},
+			want: entity.Refund{},
+			err:  myErrors.ReturnWindowExpiredErr,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryLock).WithArgs(inventoryId, userId).
+					WillReturnRows(lockRows(&merchId, boughtAt, false))
+				m.ExpectExec(queryReturn).WithArgs(inventoryId).
+					WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			want: entity.Refund{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.Return(context.Background(), userId, inventoryId, notBefore)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRefund_ListInventory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewRefund(mock)
+
+	query := `select i.id, coalesce\(m.name, ''\), case when i.cost > 0 then i.cost else coalesce\(m.cost, 0\) end, i.created_at, i.returned_at
+			from inventory as i left join merch as m on i.merch_id=m.id
+			where i.user_id=\$1
+			order by i.id desc;`
+	boughtAt := time.Date(2025, 2, 15, 12, 0, 0, 0, time.UTC)
+	returnedAt := boughtAt.Add(time.Hour)
+
+	mock.ExpectQuery(query).WithArgs(uint32(1)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "cost", "created_at", "returned_at"}).
+			AddRow(uint32(11), "cup", uint32(20), boughtAt, (*time.Time)(nil)).
+			AddRow(uint32(10), "t-shirt", uint32(80), boughtAt, &returnedAt))
+	mock.ExpectQuery(query).WithArgs(uint32(2)).WillReturnError(ErrDB)
+
+	res, err := repo.ListInventory(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.InventoryItem{
+		{ID: 11, Type: "cup", Cost: 20, BoughtAt: boughtAt},
+		{ID: 10, Type: "t-shirt", Cost: 80, BoughtAt: boughtAt, ReturnedAt: &returnedAt},
+	}, res)
+
+	res, err = repo.ListInventory(context.Background(), 2)
+	assert.Equal(t, ErrDB, err)
+	assert.Equal(t, []entity.InventoryItem{}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
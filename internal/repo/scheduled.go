@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx"
+	pgx5 "github.com/jackc/pgx/v5"
+)
+
+//go:generate mockgen -source=scheduled.go -destination=mock/scheduled_mock.go -package=mock
+type ScheduledInterface interface {
+	Create(ctx context.Context, s entity.ScheduledTransfer) (entity.ScheduledTransfer, error)
+	List(ctx context.Context, userId uint32) ([]entity.ScheduledTransfer, error)
+	Update(ctx context.Context, s entity.ScheduledTransfer) (entity.ScheduledTransfer, error)
+	Cancel(ctx context.Context, userId uint32, id uint32) error
+	RunNext(ctx context.Context, exclude []uint32) (entity.ScheduledTransfer, bool, error)
+}
+
+type Scheduled struct {
+	db DBInterface
+}
+
+func NewScheduled(db DBInterface) ScheduledInterface {
+	return &Scheduled{db: db}
+}
+
+// scheduledColumns выбирает перевод вместе с именем получателя из CTE s
+const scheduledColumns = `select s.id, s.from_user, s.to_user, coalesce(u.name, ''), s.amount, s.message, s.category,
+		s.interval_seconds, s.next_run_at, s.status, s.last_run_at, s.last_error, s.failures, s.created_at
+	from s left join "user" as u on u.id=s.to_user`
+
+func scanScheduled(row pgx5.Row) (entity.ScheduledTransfer, error) {
+	var s entity.ScheduledTransfer
+	var seconds int64
+	err := row.Scan(&s.ID, &s.FromUser, &s.ToUserID, &s.ToUser, &s.Amount, &s.Message, &s.Category,
+		&seconds, &s.NextRunAt, &s.Status, &s.LastRunAt, &s.LastError, &s.Failures, &s.CreatedAt)
+	s.Interval = entity.Interval(time.Duration(seconds) * time.Second)
+	return s, err
+}
+
+func (r *Scheduled) Create(ctx context.Context, s entity.ScheduledTransfer) (entity.ScheduledTransfer, error) {
+	query := `with s as (
+			insert into scheduled_transfer(from_user, to_user, amount, message, category, interval_seconds, next_run_at)
+			values ($1, $2, $3, $4, $5, $6, $7) returning *
+		) ` + scheduledColumns + `;`
+	seconds := int64(time.Duration(s.Interval) / time.Second)
+	return scanScheduled(r.db.QueryRow(ctx, query,
+		s.FromUser, s.ToUserID, s.Amount, s.Message, s.Category, seconds, s.NextRunAt))
+}
+
+func (r *Scheduled) List(ctx context.Context, userId uint32) ([]entity.ScheduledTransfer, error) {
+	query := `with s as (select * from scheduled_transfer where from_user=$1) ` + scheduledColumns + ` order by s.id desc;`
+	res := []entity.ScheduledTransfer{}
+	rows, err := r.db.Query(ctx, query, userId)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s, err := scanScheduled(rows)
+		if err != nil {
+			return []entity.ScheduledTransfer{}, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// Update меняет параметры активного или приостановленного перевода владельца и сбрасывает счетчик ошибок.
+// Нулевой NextRunAt оставляет время следующего запуска без изменений.
+func (r *Scheduled) Update(ctx context.Context, s entity.ScheduledTransfer) (entity.ScheduledTransfer, error) {
+	query := `with s as (
+			update scheduled_transfer set to_user=$3, amount=$4, message=$5, category=$6,
+				interval_seconds=$7, next_run_at=coalesce($8, next_run_at), status=$9, failures=0, last_error=''
+			where id=$1 and from_user=$2 and status in ('active', 'paused') returning *
+		) ` + scheduledColumns + `;`
+	seconds := int64(time.Duration(s.Interval) / time.Second)
+	var nextRun *time.Time
+	if !s.NextRunAt.IsZero() {
+		nextRun = &s.NextRunAt
+	}
+	res, err := scanScheduled(r.db.QueryRow(ctx, query,
+		s.ID, s.FromUser, s.ToUserID, s.Amount, s.Message, s.Category, seconds, nextRun, s.Status))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.ScheduledTransfer{}, myErrors.NoScheduledTransferErr
+		}
+		return entity.ScheduledTransfer{}, err
+	}
+	return res, nil
+}
+
+func (r *Scheduled) Cancel(ctx context.Context, userId uint32, id uint32) error {
+	query := `update scheduled_transfer set status='cancelled' where id=$1 and from_user=$2 and status in ('active', 'paused');`
+	tag, err := r.db.Exec(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return myErrors.NoScheduledTransferErr
+	}
+	return nil
+}
+
+// RunNext захватывает один наступивший перевод и в той же транзакции выполняет его,
+// переносит расписание вперед и сохраняет результат, поэтому запуск не может быть
+// засчитан без перевода монет. Строки, которые уже обрабатывает другой экземпляр сервиса,
+// и переводы из exclude пропускаются. Пропущенные периоды не наверстываются: следующий
+// запуск назначается на ближайший момент в будущем. Если наступивших переводов нет, ok=false.
+// При ошибке базы транзакция откатывается целиком, а в результате остается id перевода,
+// чтобы вызывающий мог пропустить его до следующего запуска обработчика.
+func (r *Scheduled) RunNext(ctx context.Context, exclude []uint32) (entity.ScheduledTransfer, bool, error) {
+	queryClaim := `with due as (
+			select id from scheduled_transfer
+			where status='active' and next_run_at<=NOW() and id <> all($1)
+			order by next_run_at
+			limit 1
+			for update skip locked
+		), s as (
+			update scheduled_transfer as t set
+				last_run_at=t.next_run_at,
+				next_run_at=case when t.interval_seconds>0
+					then t.next_run_at + make_interval(secs => (t.interval_seconds *
+						(floor(extract(epoch from NOW()-t.next_run_at) / t.interval_seconds) + 1))::double precision)
+					else t.next_run_at end,
+				status=case when t.interval_seconds>0 then t.status else 'completed' end
+			from due where t.id=due.id
+			returning t.*
+		) ` + scheduledColumns + `;`
+	// неудавшийся разовый перевод помечается как failed,
+	// повторяющийся ставится на паузу после MaxScheduleFailures ошибок подряд
+	queryResult := `update scheduled_transfer set
+			last_error=$2,
+			failures=case when $2='' then 0 else failures+1 end,
+			status=case
+				when $2='' then status
+				when interval_seconds=0 then 'failed'
+				when status='active' and failures+1>=$3 then 'paused'
+				else status end
+		where id=$1
+		returning status, failures;`
+	if exclude == nil {
+		exclude = []uint32{}
+	}
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return entity.ScheduledTransfer{}, false, err
+	}
+	defer tx.Rollback(ctx)
+	transfer, err := scanScheduled(tx.QueryRow(ctx, queryClaim, exclude))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.ScheduledTransfer{}, false, nil
+		}
+		return entity.ScheduledTransfer{}, false, err
+	}
+	err = transferCoins(ctx, tx, []entity.Transaction{{
+		From:     transfer.FromUser,
+		To:       transfer.ToUserID,
+		Amount:   transfer.Amount,
+		Message:  transfer.Message,
+		Category: transfer.Category,
+	}})
+	// отказ из-за баланса или удаленного получателя сохраняется как результат запуска,
+	// остальные ошибки откатывают весь запуск
+	transfer.LastError = ""
+	if errors.Is(err, myErrors.NotEnoughCoinErr) || errors.Is(err, myErrors.NoUserErr) {
+		transfer.LastError = err.Error()
+	} else if err != nil {
+		return entity.ScheduledTransfer{ID: transfer.ID}, false, err
+	}
+	err = tx.QueryRow(ctx, queryResult, transfer.ID, transfer.LastError, entity.MaxScheduleFailures).
+		Scan(&transfer.Status, &transfer.Failures)
+	if err != nil {
+		return entity.ScheduledTransfer{ID: transfer.ID}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.ScheduledTransfer{ID: transfer.ID}, false, err
+	}
+	return transfer, true, nil
+}
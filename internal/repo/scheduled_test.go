@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var scheduledRows = []string{"id", "from_user", "to_user", "name", "amount", "message", "category",
+	"interval_seconds", "next_run_at", "status", "last_run_at", "last_error", "failures", "created_at"}
+
+func TestScheduled_RunNext(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewScheduled(mock)
+
+	queryClaim := `select id from scheduled_transfer where status='active' and next_run_at<=NOW\(\) and id <> all\(\$1\) order by next_run_at limit 1 for update skip locked`
+	queryLock := `select id from "user" where id=\$1 for update;`
+	queryDebit := `update "user" set coins=coins-\$1 where id=\$2 and coins>=\$1 returning coins;`
+	queryCredit := `update "user" set coins=coins\+\$1 where id=\$2;`
+	queryHistory := `insert into coin_history`
+	queryResult := `update scheduled_transfer set last_error=\$2`
+	runAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	nextRun := runAt.Add(7 * 24 * time.Hour)
+	claimed := func() *pgxmock.Rows {
+		return pgxmock.NewRows(scheduledRows).
+			AddRow(uint32(1), uint32(1), uint32(2), "mary", uint32(10), "спасибо", "thanks",
+				int64(7*24*3600), nextRun, "active", &runAt, "", 0, runAt)
+	}
+	transfer := entity.ScheduledTransfer{
+		ID: 1, FromUser: 1, ToUserID: 2, ToUser: "mary", Amount: 10, Message: "спасибо", Category: "thanks",
+		Interval: entity.Interval(7 * 24 * time.Hour), NextRunAt: nextRun, Status: "active", LastRunAt: &runAt, CreatedAt: runAt,
+	}
+	historyId := uint32(7)
+	lockUsers := func(m pgxmock.PgxPoolIface) {
+		m.ExpectQuery(queryLock).WithArgs(uint32(1)).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(1)))
+		m.ExpectQuery(queryLock).WithArgs(uint32(2)).WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(2)))
+	}
+
+	tests := []struct {
+		name    string
+		exclude []uint32
+		mock    func(m pgxmock.PgxPoolIface)
+		want    entity.ScheduledTransfer
+		ok      bool
+		err     error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryClaim).WithArgs([]uint32{}).WillReturnRows(claimed())
+				lockUsers(m)
+				m.ExpectQuery(queryDebit).WithArgs(uint32(10), uint32(1)).
+					WillReturnRows(pgxmock.NewRows([]string{"coins"}).AddRow(uint32(90)))
+				m.ExpectExec(queryCredit).WithArgs(uint32(10), uint32(2)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				m.ExpectQuery(queryHistory).WithArgs(uint32(1), uint32(2), uint32(10), "спасибо", "thanks").
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(historyId))
+				expectLedger(m, entity.LedgerTransfer, &historyId,
+					entity.UserEntry(1, -10), entity.UserEntry(2, 10)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectQuery(queryResult).WithArgs(uint32(1), "", entity.MaxScheduleFailures).
+					WillReturnRows(pgxmock.NewRows([]string{"status", "failures"}).AddRow("active", 0))
+				m.ExpectCommit()
+			},
+			want: transfer,
+			ok:   true,
+			err:  nil,
+		},
+		{
+			name:    "Success, not enough coins is recorded",
+			exclude: []uint32{3},
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryClaim).WithArgs([]uint32{3}).WillReturnRows(claimed())
+				lockUsers(m)
+				m.ExpectQuery(queryDebit).WithArgs(uint32(10), uint32(1)).WillReturnError(pgx.ErrNoRows)
+				m.ExpectQuery(queryResult).WithArgs(uint32(1), myErrors.NotEnoughCoinErr.Error(), entity.MaxScheduleFailures).
+					WillReturnRows(pgxmock.NewRows([]string{"status", "failures"}).AddRow("active", 1))
+				m.ExpectCommit()
+			},
+			want: func() entity.ScheduledTransfer {
+				res := transfer
+				res.LastError = myErrors.NotEnoughCoinErr.Error()
+				res.Failures = 1
+				return res
+			}(),
+			ok:  true,
+			err: nil,
+		},
+		{
+			name: "Success, nothing due",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryClaim).WithArgs([]uint32{}).WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: entity.ScheduledTransfer{},
+			ok:   false,
+			err:  nil,
+		},
+		{
+			name: "Fail, db error rolls back the run",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryClaim).WithArgs([]uint32{}).WillReturnRows(claimed())
+				lockUsers(m)
+				m.ExpectQuery(queryDebit).WithArgs(uint32(10), uint32(1)).WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			want: entity.ScheduledTransfer{ID: 1},
+			ok:   false,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, ok, err := repo.RunNext(context.Background(), tt.exclude)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestScheduled_Update(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewScheduled(mock)
+
+	query := `update scheduled_transfer set .* next_run_at=coalesce\(\$8, next_run_at\)`
+	transfer := entity.ScheduledTransfer{ID: 5, FromUser: 1, ToUserID: 2, Amount: 20, Status: entity.ScheduleActive}
+
+	mock.ExpectQuery(query).
+		WithArgs(uint32(5), uint32(1), uint32(2), uint32(20), "", "", int64(0), (*time.Time)(nil), "active").
+		WillReturnError(pgx.ErrNoRows)
+	_, err = repo.Update(context.Background(), transfer)
+	assert.Equal(t, myErrors.NoScheduledTransferErr, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduled_Cancel(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewScheduled(mock)
+
+	query := `update scheduled_transfer set status='cancelled' where id=\$1 and from_user=\$2 and status in \('active', 'paused'\)`
+	mock.ExpectExec(query).WithArgs(uint32(5), uint32(1)).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(query).WithArgs(uint32(6), uint32(1)).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	assert.NoError(t, repo.Cancel(context.Background(), 1, 5))
+	assert.Equal(t, myErrors.NoScheduledTransferErr, repo.Cancel(context.Background(), 1, 6))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
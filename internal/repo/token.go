@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+//go:generate mockgen -source=token.go -destination=mock/token_mock.go -package=mock
+type TokenInterface interface {
+	CreateRefresh(ctx context.Context, token entity.RefreshToken) error
+	GetRefresh(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	RotateRefresh(ctx context.Context, oldId uint32, next entity.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, family string) error
+	RevokeAccess(ctx context.Context, token entity.AccessToken) error
+	ListRevoked(ctx context.Context, since time.Time) ([]entity.RevokedToken, error)
+	ListCutoffs(ctx context.Context, since time.Time) ([]entity.TokenCutoff, error)
+}
+
+type Token struct {
+	db DBInterface
+}
+
+func NewToken(db DBInterface) TokenInterface {
+	return &Token{db: db}
+}
+
+func (t *Token) CreateRefresh(ctx context.Context, token entity.RefreshToken) error {
+	query := `insert into refresh_token(user_id, token_hash, family, expires_at) values ($1, $2, $3, $4);`
+	_, err := t.db.Exec(ctx, query, token.UserID, token.TokenHash, token.Family, token.ExpiresAt)
+	return err
+}
+
+func (t *Token) GetRefresh(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	query := `select id, user_id, token_hash, family, expires_at, revoked_at from refresh_token where token_hash=$1;`
+	var res entity.RefreshToken
+	err := t.db.QueryRow(ctx, query, tokenHash).
+		Scan(&res.ID, &res.UserID, &res.TokenHash, &res.Family, &res.ExpiresAt, &res.RevokedAt)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RotateRefresh отзывает использованный токен и сохраняет следующий в одной транзакции.
+// Возвращает false, если токен уже был отозван параллельным запросом.
+func (t *Token) RotateRefresh(ctx context.Context, oldId uint32, next entity.RefreshToken) (bool, error) {
+	queryRevoke := `update refresh_token set revoked_at=NOW() where id=$1 and revoked_at is null returning id;`
+	queryInsert := `insert into refresh_token(user_id, token_hash, family, expires_at) values ($1, $2, $3, $4);`
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	var id uint32
+	err = tx.QueryRow(ctx, queryRevoke, oldId).Scan(&id)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return false, nil
+		}
+		return false, err
+	}
+	_, err = tx.Exec(ctx, queryInsert, next.UserID, next.TokenHash, next.Family, next.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *Token) RevokeFamily(ctx context.Context, family string) error {
+	query := `update refresh_token set revoked_at=NOW() where family=$1 and revoked_at is null;`
+	_, err := t.db.Exec(ctx, query, family)
+	return err
+}
+
+func (t *Token) RevokeAccess(ctx context.Context, token entity.AccessToken) error {
+	query := `insert into revoked_token(jti, expires_at) values ($1, $2) on conflict (jti) do nothing;`
+	_, err := t.db.Exec(ctx, query, token.ID, token.ExpiresAt)
+	return err
+}
+
+// ListRevoked возвращает еще не истекшие токены, отозванные начиная с since
+func (t *Token) ListRevoked(ctx context.Context, since time.Time) ([]entity.RevokedToken, error) {
+	query := `select jti, expires_at from revoked_token where revoked_at >= $1 and expires_at > NOW();`
+	res := []entity.RevokedToken{}
+	rows, err := t.db.Query(ctx, query, since)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r entity.RevokedToken
+		err := rows.Scan(&r.ID, &r.ExpiresAt)
+		if err != nil {
+			return []entity.RevokedToken{}, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// ListCutoffs возвращает времена смены пароля, записанные начиная с since
+func (t *Token) ListCutoffs(ctx context.Context, since time.Time) ([]entity.TokenCutoff, error) {
+	query := `select user_id, not_before from token_cutoff where not_before >= $1;`
+	res := []entity.TokenCutoff{}
+	rows, err := t.db.Query(ctx, query, since)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c entity.TokenCutoff
+		err := rows.Scan(&c.UserID, &c.NotBefore)
+		if err != nil {
+			return []entity.TokenCutoff{}, err
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
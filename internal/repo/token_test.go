@@ -0,0 +1,154 @@
+package repo
+
+import (
+	"avito-winter-2025/internal/entity"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken_GetRefresh(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewToken(mock)
+
+	query := `select id, user_id, token_hash, family, expires_at, revoked_at from refresh_token where token_hash=\$1`
+	expiresAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want *entity.RefreshToken
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs("hash").
+					WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "token_hash", "family", "expires_at", "revoked_at"}).
+						AddRow(uint32(5), uint32(1), "hash", "family", expiresAt, nil))
+			},
+			want: &entity.RefreshToken{ID: 5, UserID: 1, TokenHash: "hash", Family: "family", ExpiresAt: expiresAt},
+			err:  nil,
+		},
+		{
+			name: "Not found",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs("hash").WillReturnError(pgx.ErrNoRows)
+			},
+			want: nil,
+			err:  nil,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectQuery(query).WithArgs("hash").WillReturnError(ErrDB)
+			},
+			want: nil,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.GetRefresh(context.Background(), "hash")
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestToken_RotateRefresh(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewToken(mock)
+
+	queryRevoke := `update refresh_token set revoked_at=NOW\(\) where id=\$1 and revoked_at is null returning id`
+	queryInsert := `insert into refresh_token\(user_id, token_hash, family, expires_at\) values \(\$1, \$2, \$3, \$4\)`
+	next := entity.RefreshToken{UserID: 1, TokenHash: "next", Family: "family", ExpiresAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want bool
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryRevoke).WithArgs(uint32(5)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectExec(queryInsert).WithArgs(next.UserID, next.TokenHash, next.Family, next.ExpiresAt).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectCommit()
+				m.ExpectRollback()
+			},
+			want: true,
+			err:  nil,
+		},
+		{
+			name: "Already revoked",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryRevoke).WithArgs(uint32(5)).WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  nil,
+		},
+		{
+			name: "Fail, insert error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryRevoke).WithArgs(uint32(5)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(uint32(5)))
+				m.ExpectExec(queryInsert).WithArgs(next.UserID, next.TokenHash, next.Family, next.ExpiresAt).
+					WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.RotateRefresh(context.Background(), 5, next)
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestToken_ListRevoked(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewToken(mock)
+
+	query := `select jti, expires_at from revoked_token where revoked_at >= \$1 and expires_at > NOW\(\)`
+	since := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := since.Add(15 * time.Minute)
+
+	mock.ExpectQuery(query).WithArgs(since).
+		WillReturnRows(pgxmock.NewRows([]string{"jti", "expires_at"}).AddRow("jti", expiresAt))
+	res, err := repo.ListRevoked(context.Background(), since)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.RevokedToken{{ID: "jti", ExpiresAt: expiresAt}}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
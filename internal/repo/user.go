@@ -14,8 +14,13 @@ var COINS = 1000
 //go:generate mockgen -source=user.go -destination=mock/user_mock.go -package=mock
 type UserInterface interface {
 	GetUser(ctx context.Context, name string, id uint32) (*entity.User, error)
+	GetByNames(ctx context.Context, names []string) ([]entity.User, error)
 	CreateUser(ctx context.Context, name string, password string) (entity.User, error)
 	GetPassword(ctx context.Context, id uint32) (entity.Password, error)
+	SetPassword(ctx context.Context, id uint32, password string) error
+	CreatePasswordReset(ctx context.Context, reset entity.PasswordResetToken) error
+	ResetPassword(ctx context.Context, tokenHash string, password string) (bool, error)
+	SetRole(ctx context.Context, name string, role string) (entity.User, error)
 }
 
 type User struct {
@@ -30,7 +35,7 @@ func (u *User) GetUser(ctx context.Context, name string, id uint32) (*entity.Use
 	if name == "" && id == 0 {
 		return nil, myErrors.NoUserErr
 	}
-	query1 := `select id, name, coins from "user" where `
+	query1 := `select id, name, coins, role from "user" where `
 	query2 := `=$1`
 	var query string
 	var res entity.User
@@ -42,7 +47,7 @@ func (u *User) GetUser(ctx context.Context, name string, id uint32) (*entity.Use
 		query = query1 + `id` + query2
 		row = u.db.QueryRow(ctx, query, id)
 	}
-	err := row.Scan(&res.ID, &res.Name, &res.Coins)
+	err := row.Scan(&res.ID, &res.Name, &res.Coins, &res.Role)
 	if err != nil {
 		if err.Error() == pgx.ErrNoRows.Error() {
 			return nil, nil
@@ -52,18 +57,55 @@ func (u *User) GetUser(ctx context.Context, name string, id uint32) (*entity.Use
 	return &res, nil
 }
 
+// GetByNames возвращает найденных пользователей из списка имен; отсутствующие имена пропускаются
+func (u *User) GetByNames(ctx context.Context, names []string) ([]entity.User, error) {
+	query := `select id, name, coins, role from "user" where name = any($1) order by id;`
+	res := []entity.User{}
+	rows, err := u.db.Query(ctx, query, names)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user entity.User
+		err := rows.Scan(&user.ID, &user.Name, &user.Coins, &user.Role)
+		if err != nil {
+			return []entity.User{}, err
+		}
+		res = append(res, user)
+	}
+	return res, nil
+}
+
+// CreateUser создает пользователя и в той же транзакции начисляет ему стартовые монеты в журнале
 func (u *User) CreateUser(ctx context.Context, name string, password string) (entity.User, error) {
-	query := `insert into "user"(name, password, coins) values ($1, $2, $3) returning id, name, coins;`
+	query := `insert into "user"(name, password, coins) values ($1, $2, $3) returning id, name, coins, role;`
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return entity.User{}, err
+	}
+	defer tx.Rollback(ctx)
 	var res entity.User
-	err := u.db.QueryRow(ctx, query, name, password, COINS).Scan(&res.ID, &res.Name, &res.Coins)
+	err = tx.QueryRow(ctx, query, name, password, COINS).Scan(&res.ID, &res.Name, &res.Coins, &res.Role)
 	if err != nil {
-		if pgErr, ok := err.(pgx.PgError); ok {
-			if pgErr.Code == "23505" {
-				return entity.User{}, myErrors.NotUnique
-			}
+		if isUniqueViolation(err) {
+			return entity.User{}, myErrors.NotUnique
 		}
 		return entity.User{}, err
 	}
+	err = postLedger(ctx, tx, entity.LedgerTransaction{
+		Kind: entity.LedgerInitialGrant,
+		Entries: []entity.LedgerEntry{
+			entity.SystemEntry(entity.AccountIssuance, -int64(COINS)),
+			entity.UserEntry(res.ID, int64(COINS)),
+		},
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.User{}, err
+	}
 	return res, nil
 }
 
@@ -76,3 +118,114 @@ func (u *User) GetPassword(ctx context.Context, id uint32) (entity.Password, err
 	}
 	return entity.Password(res), nil
 }
+
+// SetPassword меняет пароль и отзывает все токены пользователя
+func (u *User) SetPassword(ctx context.Context, id uint32, password string) error {
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := setPassword(ctx, tx, id, password); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CreatePasswordReset сохраняет новый токен сброса; ранее выданные неиспользованные токены перестают действовать
+func (u *User) CreatePasswordReset(ctx context.Context, reset entity.PasswordResetToken) error {
+	queryExpire := `update password_reset set expires_at=NOW() where user_id=$1 and used_at is null and expires_at > NOW();`
+	queryInsert := `insert into password_reset(user_id, token_hash, created_by, expires_at) values ($1, $2, $3, $4);`
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, queryExpire, reset.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, queryInsert, reset.UserID, reset.TokenHash, reset.CreatedBy, reset.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ResetPassword погашает токен сброса и меняет пароль в одной транзакции.
+// Возвращает false, если токен не найден, истек или уже использован.
+func (u *User) ResetPassword(ctx context.Context, tokenHash string, password string) (bool, error) {
+	query := `update password_reset set used_at=NOW()
+		where token_hash=$1 and used_at is null and expires_at > NOW() returning user_id;`
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	var userId uint32
+	err = tx.QueryRow(ctx, query, tokenHash).Scan(&userId)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := setPassword(ctx, tx, userId, password); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setPassword сохраняет хэш пароля, отзывает refresh-токены и запоминает время смены,
+// после которого старые access-токены перестают приниматься
+func setPassword(ctx context.Context, tx pgx5.Tx, id uint32, password string) error {
+	queryUpdate := `update "user" set password=$2 where id=$1;`
+	queryRevoke := `update refresh_token set revoked_at=NOW() where user_id=$1 and revoked_at is null;`
+	queryCutoff := `insert into token_cutoff(user_id, not_before) values ($1, NOW())
+		on conflict (user_id) do update set not_before=excluded.not_before;`
+	tag, err := tx.Exec(ctx, queryUpdate, id, password)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return myErrors.NoUserErr
+	}
+	_, err = tx.Exec(ctx, queryRevoke, id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, queryCutoff, id)
+	return err
+}
+
+// SetRole меняет роль пользователя. Роль зашита в access-токен, поэтому ранее
+// выданные токены перестают приниматься и клиент получит новую роль при обновлении.
+func (u *User) SetRole(ctx context.Context, name string, role string) (entity.User, error) {
+	queryUpdate := `update "user" set role=$2 where name=$1 returning id, name, coins, role;`
+	queryCutoff := `insert into token_cutoff(user_id, not_before) values ($1, NOW())
+		on conflict (user_id) do update set not_before=excluded.not_before;`
+	tx, err := u.db.Begin(ctx)
+	if err != nil {
+		return entity.User{}, err
+	}
+	defer tx.Rollback(ctx)
+	var res entity.User
+	err = tx.QueryRow(ctx, queryUpdate, name, role).Scan(&res.ID, &res.Name, &res.Coins, &res.Role)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return entity.User{}, myErrors.NoUserErr
+		}
+		return entity.User{}, err
+	}
+	_, err = tx.Exec(ctx, queryCutoff, res.ID)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return entity.User{}, err
+	}
+	return res, nil
+}
@@ -2,11 +2,13 @@ package repo
 
 import (
 	"avito-winter-2025/internal/entity"
+	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
 	"errors"
 	"testing"
 
 	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -19,8 +21,8 @@ func TestUser_GetUser(t *testing.T) {
 	defer mock.Close()
 
 	repo := NewUser(mock)
-	queryName := `select id, name, coins from "user" where name=\$1`
-	queryId := `select id, name, coins from "user" where id=\$1`
+	queryName := `select id, name, coins, role from "user" where name=\$1`
+	queryId := `select id, name, coins, role from "user" where id=\$1`
 
 	tests := []struct {
 		name     string
@@ -38,10 +40,10 @@ func TestUser_GetUser(t *testing.T) {
 			query:    queryName,
 			mock: func(m pgxmock.PgxPoolIface, query string, name string, id uint32) {
 				m.ExpectQuery(query).WithArgs(name).
-					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins"}).
-						AddRow(uint32(1), "sofia", uint32(1000)))
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins", "role"}).
+						AddRow(uint32(1), "sofia", uint32(1000), "employee"))
 			},
-			want: &entity.User{ID: 1, Name: "sofia", Coins: 1000},
+			want: &entity.User{ID: 1, Name: "sofia", Coins: 1000, Role: "employee"},
 			err:  nil,
 		},
 		{
@@ -51,10 +53,10 @@ func TestUser_GetUser(t *testing.T) {
 			query:    queryId,
 			mock: func(m pgxmock.PgxPoolIface, query string, name string, id uint32) {
 				m.ExpectQuery(query).WithArgs(id).
-					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins"}).
-						AddRow(uint32(1), "sofia", uint32(1000)))
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins", "role"}).
+						AddRow(uint32(1), "sofia", uint32(1000), "employee"))
 			},
-			want: &entity.User{ID: 1, Name: "sofia", Coins: 1000},
+			want: &entity.User{ID: 1, Name: "sofia", Coins: 1000, Role: "employee"},
 			err:  nil,
 		},
 		{
@@ -159,7 +161,7 @@ func TestUser_CreateUser(t *testing.T) {
 	defer mock.Close()
 
 	repo := NewUser(mock)
-	queryName := `insert into "user"\(name, password, coins\) values \(\$1, \$2, \$3\) returning id, name, coins;`
+	queryName := `insert into "user"\(name, password, coins\) values \(\$1, \$2, \$3\) returning id, name, coins, role;`
 	name := "sofia"
 	password := "12345"
 	coins := 1000
@@ -175,19 +177,27 @@ func TestUser_CreateUser(t *testing.T) {
 			name:  "Success",
 			query: queryName,
 			mock: func(m pgxmock.PgxPoolIface, query string) {
+				m.ExpectBegin()
 				m.ExpectQuery(query).WithArgs(name, password, coins).
-					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins"}).
-						AddRow(uint32(1), "sofia", uint32(1000)))
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins", "role"}).
+						AddRow(uint32(1), "sofia", uint32(1000), "employee"))
+				expectLedger(m, entity.LedgerInitialGrant, nil,
+					entity.SystemEntry(entity.AccountIssuance, -int64(coins)),
+					entity.UserEntry(1, int64(coins))).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				m.ExpectCommit()
 			},
-			want: entity.User{ID: 1, Name: "sofia", Coins: 1000},
+			want: entity.User{ID: 1, Name: "sofia", Coins: 1000, Role: "employee"},
 			err:  nil,
 		},
 		{
 			name:  "Fail, this user already exists",
 			query: queryName,
 			mock: func(m pgxmock.PgxPoolIface, query string) {
+				m.ExpectBegin()
 				m.ExpectQuery(query).WithArgs(name, password, coins).
-					WillReturnError(pgx.PgError{Code: "23505"})
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+				m.ExpectRollback()
 			},
 			want: entity.User{},
 			err:  errors.New("Запись с указанными данными уже существует"),
@@ -196,8 +206,10 @@ func TestUser_CreateUser(t *testing.T) {
 			name:  "Fail",
 			query: queryName,
 			mock: func(m pgxmock.PgxPoolIface, query string) {
+				m.ExpectBegin()
 				m.ExpectQuery(query).WithArgs(name, password, coins).
 					WillReturnError(ErrDB)
+				m.ExpectRollback()
 			},
 			want: entity.User{},
 			err:  ErrDB,
@@ -210,6 +222,174 @@ func TestUser_CreateUser(t *testing.T) {
 
 			assert.Equal(t, tt.want, res)
 			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func expectSetPassword(m pgxmock.PgxPoolIface, id uint32, password string) {
+	m.ExpectExec(`update "user" set password=\$2 where id=\$1`).WithArgs(id, password).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	m.ExpectExec(`update refresh_token set revoked_at=NOW\(\) where user_id=\$1 and revoked_at is null`).WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	m.ExpectExec(`insert into token_cutoff\(user_id, not_before\) values \(\$1, NOW\(\)\)`).WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func TestUser_SetPassword(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewUser(mock)
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				expectSetPassword(m, 1, "hash")
+				m.ExpectCommit()
+			},
+			err: nil,
+		},
+		{
+			name: "Fail, no user",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectExec(`update "user" set password=\$2 where id=\$1`).WithArgs(uint32(1), "hash").
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				m.ExpectRollback()
+			},
+			err: myErrors.NoUserErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			err := repo.SetPassword(context.Background(), 1, "hash")
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUser_ResetPassword(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewUser(mock)
+
+	query := `update password_reset set used_at=NOW\(\)
+		where token_hash=\$1 and used_at is null and expires_at > NOW\(\) returning user_id`
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		want bool
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(query).WithArgs("token").
+					WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow(uint32(2)))
+				expectSetPassword(m, 2, "hash")
+				m.ExpectCommit()
+			},
+			want: true,
+			err:  nil,
+		},
+		{
+			name: "Invalid token",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(query).WithArgs("token").WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  nil,
+		},
+		{
+			name: "Fail, db error",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(query).WithArgs("token").WillReturnError(ErrDB)
+				m.ExpectRollback()
+			},
+			want: false,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.ResetPassword(context.Background(), "token", "hash")
+			assert.Equal(t, tt.want, res)
+			assert.Equal(t, tt.err, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUser_SetRole(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	repo := NewUser(mock)
+
+	queryUpdate := `update "user" set role=\$2 where name=\$1 returning id, name, coins, role;`
+	queryCutoff := `insert into token_cutoff\(user_id, not_before\) values \(\$1, NOW\(\)\)`
+
+	tests := []struct {
+		name string
+		mock func(m pgxmock.PgxPoolIface)
+		res  entity.User
+		err  error
+	}{
+		{
+			name: "Success",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryUpdate).WithArgs("alice", entity.RoleAdmin).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "name", "coins", "role"}).
+						AddRow(uint32(1), "alice", uint32(1000), entity.RoleAdmin))
+				m.ExpectExec(queryCutoff).WithArgs(uint32(1)).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				m.ExpectCommit()
+			},
+			res: entity.User{ID: 1, Name: "alice", Coins: 1000, Role: entity.RoleAdmin},
+			err: nil,
+		},
+		{
+			name: "Fail, no user",
+			mock: func(m pgxmock.PgxPoolIface) {
+				m.ExpectBegin()
+				m.ExpectQuery(queryUpdate).WithArgs("alice", entity.RoleAdmin).
+					WillReturnError(pgx.ErrNoRows)
+				m.ExpectRollback()
+			},
+			res: entity.User{},
+			err: myErrors.NoUserErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock(mock)
+			res, err := repo.SetRole(context.Background(), "alice", entity.RoleAdmin)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.res, res)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"context"
+	"time"
+)
+
+type AllowanceInterface interface {
+	Run(ctx context.Context) (int, error)
+	Preview(ctx context.Context) (entity.AllowancePreview, error)
+	ListGrants(ctx context.Context, filter entity.AllowanceGrantFilter) ([]entity.AllowanceGrant, error)
+}
+
+type Allowance struct {
+	repo     repo.AllowanceInterface
+	policies []entity.AllowancePolicy
+	// batchSize - сколько начислений или сгораний выполняется в одной транзакции
+	batchSize int
+	now       func() time.Time
+}
+
+func NewAllowance(r repo.AllowanceInterface, policies []entity.AllowancePolicy, batchSize int) AllowanceInterface {
+	return &Allowance{repo: r, policies: policies, batchSize: batchSize, now: time.Now}
+}
+
+// Run начисляет монеты по всем политикам за текущий период и списывает сгоревшие начисления.
+// Повторный запуск в том же периоде начисляет только пользователям, которые еще ничего не получили.
+func (a *Allowance) Run(ctx context.Context) (int, error) {
+	now := a.now()
+	processed := 0
+	for _, p := range a.policies {
+		grant := entity.AllowanceGrant{
+			Policy:    p.Name,
+			Period:    p.Period(now),
+			Amount:    p.Amount,
+			ExpiresAt: p.ExpiresAt(now),
+		}
+		n, err := a.drain(func() (int, error) {
+			return a.repo.Grant(ctx, grant, p.Role, a.batchSize)
+		})
+		processed += n
+		if err != nil {
+			return processed, err
+		}
+	}
+	n, err := a.drain(func() (int, error) {
+		return a.repo.ExpireDue(ctx, a.batchSize)
+	})
+	return processed + n, err
+}
+
+// drain повторяет batch, пока он обрабатывает полные порции
+func (a *Allowance) drain(batch func() (int, error)) (int, error) {
+	total := 0
+	for {
+		n, err := batch()
+		total += n
+		if err != nil || n < a.batchSize || n == 0 {
+			return total, err
+		}
+	}
+}
+
+// Preview показывает, кому и сколько будет начислено при следующем запуске, ничего не изменяя
+func (a *Allowance) Preview(ctx context.Context) (entity.AllowancePreview, error) {
+	now := a.now()
+	res := entity.AllowancePreview{DryRun: true, Credits: []entity.AllowanceCredit{}}
+	for _, p := range a.policies {
+		period := p.Period(now)
+		credits, err := a.repo.Preview(ctx, p.Name, period, p.Role)
+		if err != nil {
+			return entity.AllowancePreview{}, err
+		}
+		for _, c := range credits {
+			c.Policy, c.Period, c.Amount = p.Name, period, p.Amount
+			res.Credits = append(res.Credits, c)
+			res.Total += uint64(p.Amount)
+		}
+	}
+	return res, nil
+}
+
+func (a *Allowance) ListGrants(ctx context.Context, filter entity.AllowanceGrantFilter) ([]entity.AllowanceGrant, error) {
+	if filter.Limit == 0 {
+		filter.Limit = entity.DefaultHistoryLimit
+	}
+	return a.repo.ListGrants(ctx, filter)
+}
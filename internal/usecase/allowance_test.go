@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPolicies = []entity.AllowancePolicy{
+	{Name: "monthly", Amount: 200, PeriodMonths: 1, ExpireAfterMonths: 3, Role: entity.RoleEmployee},
+	{Name: "quarterly", Amount: 500, PeriodMonths: 3},
+}
+
+func TestAllowanceUsecase_Run(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	allowanceRepo := mock.NewMockAllowanceInterface(ctl)
+	now := time.Date(2025, 8, 17, 15, 30, 0, 0, time.UTC)
+	usecase := &Allowance{repo: allowanceRepo, policies: testPolicies, batchSize: 2, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	expiresAt := time.Date(2025, 11, 17, 15, 30, 0, 0, time.UTC)
+	monthly := entity.AllowanceGrant{Policy: "monthly", Period: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), Amount: 200, ExpiresAt: &expiresAt}
+	quarterly := entity.AllowanceGrant{Policy: "quarterly", Period: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Amount: 500}
+	gomock.InOrder(
+		// полные порции повторяются, пока не останется пользователей
+		allowanceRepo.EXPECT().Grant(ctx, monthly, entity.RoleEmployee, 2).Return(2, nil),
+		allowanceRepo.EXPECT().Grant(ctx, monthly, entity.RoleEmployee, 2).Return(1, nil),
+		allowanceRepo.EXPECT().Grant(ctx, quarterly, "", 2).Return(0, nil),
+		allowanceRepo.EXPECT().ExpireDue(ctx, 2).Return(1, nil),
+	)
+
+	processed, err := usecase.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, processed)
+
+	allowanceRepo.EXPECT().Grant(ctx, monthly, entity.RoleEmployee, 2).Return(0, ErrDB)
+	_, err = usecase.Run(ctx)
+	assert.Equal(t, ErrDB, err)
+}
+
+func TestAllowanceUsecase_Preview(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	allowanceRepo := mock.NewMockAllowanceInterface(ctl)
+	now := time.Date(2025, 8, 17, 15, 30, 0, 0, time.UTC)
+	usecase := &Allowance{repo: allowanceRepo, policies: testPolicies, batchSize: 2, now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	august := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	allowanceRepo.EXPECT().Preview(ctx, "monthly", august, entity.RoleEmployee).
+		Return([]entity.AllowanceCredit{{UserID: 1, User: "sofia"}, {UserID: 2, User: "mary"}}, nil)
+	allowanceRepo.EXPECT().Preview(ctx, "quarterly", july, "").
+		Return([]entity.AllowanceCredit{{UserID: 1, User: "sofia"}}, nil)
+
+	res, err := usecase.Preview(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.AllowancePreview{
+		DryRun: true,
+		Credits: []entity.AllowanceCredit{
+			{Policy: "monthly", Period: august, UserID: 1, User: "sofia", Amount: 200},
+			{Policy: "monthly", Period: august, UserID: 2, User: "mary", Amount: 200},
+			{Policy: "quarterly", Period: july, UserID: 1, User: "sofia", Amount: 500},
+		},
+		Total: 900,
+	}, res)
+}
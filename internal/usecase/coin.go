@@ -5,11 +5,15 @@ import (
 	"avito-winter-2025/internal/repo"
 	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
+	"slices"
 )
 
 type CoinInterface interface {
-	SendCoin(ctx context.Context, from uint32, to uint32, amount uint32) error
+	SendCoin(ctx context.Context, from uint32, to uint32, amount uint32, note entity.TransferNote) error
+	SendCoinBatch(ctx context.Context, from uint32, data entity.BatchSendCoinRequest) (entity.BatchSendCoinResponse, error)
 	GetCoinHistory(ctx context.Context, id uint32) (entity.CoinHistory, error)
+	GetCoinHistoryPage(ctx context.Context, filter entity.CoinHistoryFilter) (entity.CoinHistoryPage, error)
+	GetBalance(ctx context.Context, id uint32) (uint32, error)
 }
 
 type Coin struct {
@@ -21,50 +25,137 @@ func NewCoin(c repo.CoinInterface, u repo.UserInterface) CoinInterface {
 	return &Coin{coinRepo: c, userRepo: u}
 }
 
-func (u *Coin) SendCoin(ctx context.Context, from uint32, to uint32, amount uint32) error {
-	fromBalance, err := u.coinRepo.CheckBalance(ctx, from)
+func (u *Coin) SendCoin(ctx context.Context, from uint32, to uint32, amount uint32, note entity.TransferNote) error {
+	// баланс проверяется в репозитории внутри транзакции
+	err := u.coinRepo.SendCoin(ctx, entity.Transaction{
+		From:     from,
+		To:       to,
+		Amount:   amount,
+		Message:  note.Message,
+		Category: note.Category,
+	})
 	if err != nil {
 		return err
 	}
-	if fromBalance < amount {
-		return myErrors.NotEnoughCoinErr
+	return nil
+}
+
+// SendCoinBatch проверяет всех получателей и выполняет переводы одной транзакцией.
+// Если хотя бы одного получателя нет, не выполняется ни один перевод.
+func (u *Coin) SendCoinBatch(ctx context.Context, from uint32, data entity.BatchSendCoinRequest) (entity.BatchSendCoinResponse, error) {
+	names := []string{}
+	for _, t := range data.Transfers {
+		if !slices.Contains(names, t.ToUser) {
+			names = append(names, t.ToUser)
+		}
 	}
-	err = u.coinRepo.SendCoin(ctx, entity.Transaction{From: from, To: to, Amount: amount})
+	users, err := u.userRepo.GetByNames(ctx, names)
 	if err != nil {
-		return err
+		return entity.BatchSendCoinResponse{}, err
 	}
-	return nil
+	ids := map[string]uint32{}
+	for _, user := range users {
+		ids[user.Name] = user.ID
+	}
+	unknown := []string{}
+	for _, name := range names {
+		if _, ok := ids[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return entity.BatchSendCoinResponse{}, &myErrors.UnknownUsersError{Names: unknown}
+	}
+	res := entity.BatchSendCoinResponse{Count: len(data.Transfers)}
+	transactions := make([]entity.Transaction, 0, len(data.Transfers))
+	for _, t := range data.Transfers {
+		note := t.Note()
+		transactions = append(transactions, entity.Transaction{
+			From:     from,
+			To:       ids[t.ToUser],
+			Amount:   uint32(t.Amount),
+			Message:  note.Message,
+			Category: note.Category,
+		})
+		res.Total += uint64(t.Amount)
+	}
+	// баланс проверяется по общей сумме в репозитории внутри транзакции
+	if err := u.coinRepo.SendCoinBatch(ctx, transactions); err != nil {
+		return entity.BatchSendCoinResponse{}, err
+	}
+	return res, nil
+}
+
+// GetBalance возвращает баланс по журналу проводок; "user".coins служит лишь кэшем
+// для проверки при списании и сверяется с журналом командой reconcile
+func (u *Coin) GetBalance(ctx context.Context, id uint32) (uint32, error) {
+	return u.coinRepo.CheckBalance(ctx, id)
 }
 
 func (u *Coin) GetCoinHistory(ctx context.Context, id uint32) (entity.CoinHistory, error) {
 	received := []entity.Received{}
 	sent := []entity.Sent{}
+	empty := entity.CoinHistory{Received: received, Sent: sent, Refunds: []entity.Refund{}}
+	// репозиторий возвращает историю вместе с именами участников одним запросом
 	res, err := u.coinRepo.GetCoinHistory(ctx, id)
 	if err != nil {
-		return entity.CoinHistory{Received: received, Sent: sent}, err
+		return empty, err
+	}
+	refunds, err := u.coinRepo.GetRefunds(ctx, id)
+	if err != nil {
+		return empty, err
 	}
 	for _, trans := range res {
 		if trans.From == id {
-			toUser, err := u.userRepo.GetUser(ctx, "", trans.To)
-			if err != nil {
-				return entity.CoinHistory{Received: received, Sent: sent}, err
-			}
 			sent = append(sent, entity.Sent{
-				ToUser: toUser.Name,
-				Amount: trans.Amount,
+				ID:        trans.ID,
+				ToUser:    userName(trans.ToName),
+				Amount:    trans.Amount,
+				Message:   trans.Message,
+				Category:  trans.Category,
+				CreatedAt: trans.CreatedAt,
 			})
 			continue
 		}
 		if trans.To == id {
-			fromUser, err := u.userRepo.GetUser(ctx, "", trans.From)
-			if err != nil {
-				return entity.CoinHistory{Received: received, Sent: sent}, err
-			}
 			received = append(received, entity.Received{
-				FromUser: fromUser.Name,
-				Amount:   trans.Amount,
+				ID:        trans.ID,
+				FromUser:  userName(trans.FromName),
+				Amount:    trans.Amount,
+				Message:   trans.Message,
+				Category:  trans.Category,
+				CreatedAt: trans.CreatedAt,
 			})
 		}
 	}
-	return entity.CoinHistory{Received: received, Sent: sent}, nil
+	return entity.CoinHistory{Received: received, Sent: sent, Refunds: refunds}, nil
+}
+
+func userName(name string) string {
+	if name == "" {
+		return entity.DeletedUserName
+	}
+	return name
+}
+
+func (u *Coin) GetCoinHistoryPage(ctx context.Context, filter entity.CoinHistoryFilter) (entity.CoinHistoryPage, error) {
+	if filter.Limit == 0 {
+		filter.Limit = entity.DefaultHistoryLimit
+	}
+	limit := filter.Limit
+	// запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit++
+	entries, err := u.coinRepo.GetCoinHistoryPage(ctx, filter)
+	if err != nil {
+		return entity.CoinHistoryPage{Entries: []entity.HistoryEntry{}}, err
+	}
+	for i := range entries {
+		entries[i].Counterparty = userName(entries[i].Counterparty)
+	}
+	res := entity.CoinHistoryPage{Entries: entries}
+	if len(entries) > limit {
+		res.Entries = entries[:limit]
+		res.NextCursor = entries[limit-1].ID
+	}
+	return res, nil
 }
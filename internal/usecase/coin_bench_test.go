@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+// BenchmarkCoinUsecase_GetCoinHistory проверяет, что история из 5000 переводов
+// собирается за один запрос к репозиторию, без обращений к userRepo на каждую запись.
+func BenchmarkCoinUsecase_GetCoinHistory(b *testing.B) {
+	const transfers = 5000
+	id := uint32(1)
+	history := make([]entity.Transaction, 0, transfers)
+	for i := 0; i < transfers; i++ {
+		trans := entity.Transaction{ID: uint32(transfers - i), From: id, To: uint32(i + 2), FromName: "sofia", ToName: "mary", Amount: 1}
+		if i%2 == 1 {
+			trans.From, trans.To = trans.To, id
+			trans.FromName, trans.ToName = "mary", "sofia"
+		}
+		history = append(history, trans)
+	}
+
+	ctl := gomock.NewController(b)
+	defer ctl.Finish()
+	coinRepo := mock.NewMockCoinInterface(ctl)
+	// любой вызов userRepo провалит бенчмарк: ожиданий для него нет
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewCoin(coinRepo, userRepo)
+
+	queries := 0
+	coinRepo.EXPECT().GetCoinHistory(gomock.Any(), id).
+		DoAndReturn(func(ctx context.Context, id uint32) ([]entity.Transaction, error) {
+			queries++
+			return history, nil
+		}).AnyTimes()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := usecase.GetCoinHistory(context.Background(), id)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(res.Sent)+len(res.Received) != transfers {
+			b.Fatalf("got %d entries, want %d", len(res.Sent)+len(res.Received), transfers)
+		}
+	}
+	b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+}
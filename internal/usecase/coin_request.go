@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"time"
+)
+
+type CoinRequestInterface interface {
+	Create(ctx context.Context, userId uint32, data entity.CreateCoinRequest) (entity.CoinRequest, error)
+	List(ctx context.Context, userId uint32, direction string) ([]entity.CoinRequest, error)
+	Accept(ctx context.Context, userId uint32, id uint32) (entity.CoinRequest, error)
+	Decline(ctx context.Context, userId uint32, id uint32) (entity.CoinRequest, error)
+}
+
+type CoinRequest struct {
+	repo     repo.CoinRequestInterface
+	userRepo repo.UserInterface
+	// ttl - через сколько неоплаченный запрос истекает
+	ttl time.Duration
+}
+
+func NewCoinRequest(r repo.CoinRequestInterface, u repo.UserInterface, ttl time.Duration) CoinRequestInterface {
+	return &CoinRequest{repo: r, userRepo: u, ttl: ttl}
+}
+
+func (c *CoinRequest) Create(ctx context.Context, userId uint32, data entity.CreateCoinRequest) (entity.CoinRequest, error) {
+	payer, err := c.userRepo.GetUser(ctx, data.FromUser, 0)
+	if err != nil {
+		return entity.CoinRequest{}, err
+	}
+	if payer == nil {
+		return entity.CoinRequest{}, myErrors.NoUserErr
+	}
+	if payer.ID == userId {
+		return entity.CoinRequest{}, myErrors.InvalidCoinRequestErr
+	}
+	note := data.Note()
+	return c.repo.Create(ctx, entity.CoinRequest{
+		RequesterID: userId,
+		PayerID:     payer.ID,
+		Amount:      uint32(data.Amount),
+		Message:     note.Message,
+		Category:    note.Category,
+		ExpiresAt:   time.Now().Add(c.ttl),
+	})
+}
+
+func (c *CoinRequest) List(ctx context.Context, userId uint32, direction string) ([]entity.CoinRequest, error) {
+	return c.repo.List(ctx, userId, direction)
+}
+
+// Accept оплачивает запрос переводом от плательщика к автору запроса.
+// Баланс проверяется в репозитории в той же транзакции, что и смена статуса.
+func (c *CoinRequest) Accept(ctx context.Context, userId uint32, id uint32) (entity.CoinRequest, error) {
+	return c.repo.Accept(ctx, userId, id)
+}
+
+func (c *CoinRequest) Decline(ctx context.Context, userId uint32, id uint32) (entity.CoinRequest, error) {
+	return c.repo.Decline(ctx, userId, id)
+}
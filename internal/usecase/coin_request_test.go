@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoinRequestUsecase_Create(t *testing.T) {
+	data := entity.CreateCoinRequest{FromUser: "mary", Amount: 10, Message: " за обед ", Category: "Lunch"}
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface, userRepo *mock.MockUserInterface)
+		err      error
+	}{
+		{
+			name: "No payer",
+			repoMock: func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(nil, nil)
+			},
+			err: myErrors.NoUserErr,
+		},
+		{
+			name: "Request to self",
+			repoMock: func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(&entity.User{ID: 1, Name: "mary"}, nil)
+			},
+			err: myErrors.InvalidCoinRequestErr,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(&entity.User{ID: 2, Name: "mary"}, nil)
+				requestRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req entity.CoinRequest) (entity.CoinRequest, error) {
+						assert.Equal(t, uint32(1), req.RequesterID)
+						assert.Equal(t, uint32(2), req.PayerID)
+						assert.Equal(t, uint32(10), req.Amount)
+						assert.Equal(t, "за обед", req.Message)
+						assert.Equal(t, "lunch", req.Category)
+						assert.WithinDuration(t, time.Now().Add(24*time.Hour), req.ExpiresAt, time.Minute)
+						return req, nil
+					})
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			requestRepo := mock.NewMockCoinRequestInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewCoinRequest(requestRepo, userRepo, 24*time.Hour)
+
+			tt.repoMock(context.Background(), requestRepo, userRepo)
+			_, err := usecase.Create(context.Background(), 1, data)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestCoinRequestUsecase_Accept(t *testing.T) {
+	accepted := entity.CoinRequest{ID: 3, RequesterID: 2, PayerID: 1, Amount: 10, Message: "за обед", Status: entity.CoinRequestAccepted}
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface)
+		want     entity.CoinRequest
+		err      error
+	}{
+		{
+			name: "Closed or expired",
+			repoMock: func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface) {
+				requestRepo.EXPECT().Accept(ctx, uint32(1), uint32(3)).Return(entity.CoinRequest{}, myErrors.NoCoinRequestErr)
+			},
+			want: entity.CoinRequest{},
+			err:  myErrors.NoCoinRequestErr,
+		},
+		{
+			name: "Not enough coins",
+			repoMock: func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface) {
+				requestRepo.EXPECT().Accept(ctx, uint32(1), uint32(3)).Return(entity.CoinRequest{}, myErrors.NotEnoughCoinErr)
+			},
+			want: entity.CoinRequest{},
+			err:  myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, requestRepo *mock.MockCoinRequestInterface) {
+				requestRepo.EXPECT().Accept(ctx, uint32(1), uint32(3)).Return(accepted, nil)
+			},
+			want: accepted,
+			err:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			requestRepo := mock.NewMockCoinRequestInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewCoinRequest(requestRepo, userRepo, time.Hour)
+
+			tt.repoMock(context.Background(), requestRepo)
+			got, err := usecase.Accept(context.Background(), 1, 3)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
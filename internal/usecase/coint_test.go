@@ -15,6 +15,7 @@ type CoinArgs struct {
 	From   uint32
 	To     uint32
 	Amount uint32
+	Note   entity.TransferNote
 }
 
 func TestCoinUsecase_SendCoin(t *testing.T) {
@@ -25,21 +26,13 @@ func TestCoinUsecase_SendCoin(t *testing.T) {
 		want     error
 	}{
 		{
-			name: "Err in CheckBalance",
+			name: "Err Not enough money",
 			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, from, to, amount uint32) {
-				coinRepo.EXPECT().CheckBalance(ctx, from).Return(uint32(0), ErrDB)
-			},
-			args: CoinArgs{
-				From:   1,
-				To:     2,
-				Amount: 20,
-			},
-			want: ErrDB,
-		},
-		{
-			name: "Err Not enough money, balance less than amount",
-			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, from, to, amount uint32) {
-				coinRepo.EXPECT().CheckBalance(ctx, from).Return(amount-1, nil)
+				coinRepo.EXPECT().SendCoin(ctx, entity.Transaction{
+					From:   from,
+					To:     to,
+					Amount: amount,
+				}).Return(myErrors.NotEnoughCoinErr)
 			},
 			args: CoinArgs{
 				From:   1,
@@ -51,7 +44,6 @@ func TestCoinUsecase_SendCoin(t *testing.T) {
 		{
 			name: "Err in SendCoin",
 			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, from, to, amount uint32) {
-				coinRepo.EXPECT().CheckBalance(ctx, from).Return(amount+1, nil)
 				coinRepo.EXPECT().SendCoin(ctx, entity.Transaction{
 					From:   from,
 					To:     to,
@@ -68,17 +60,19 @@ func TestCoinUsecase_SendCoin(t *testing.T) {
 		{
 			name: "Success",
 			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, from, to, amount uint32) {
-				coinRepo.EXPECT().CheckBalance(ctx, from).Return(amount+1, nil)
 				coinRepo.EXPECT().SendCoin(ctx, entity.Transaction{
-					From:   from,
-					To:     to,
-					Amount: amount,
+					From:     from,
+					To:       to,
+					Amount:   amount,
+					Message:  "спасибо за ревью",
+					Category: "thanks",
 				}).Return(nil)
 			},
 			args: CoinArgs{
 				From:   1,
 				To:     2,
 				Amount: 20,
+				Note:   entity.TransferNote{Message: "спасибо за ревью", Category: "thanks"},
 			},
 			want: nil,
 		},
@@ -92,7 +86,7 @@ func TestCoinUsecase_SendCoin(t *testing.T) {
 			usecase := NewCoin(coinRepo, userRepo)
 
 			tt.repoMock(context.Background(), userRepo, coinRepo, tt.args.From, tt.args.To, tt.args.Amount)
-			got := usecase.SendCoin(context.Background(), tt.args.From, tt.args.To, tt.args.Amount)
+			got := usecase.SendCoin(context.Background(), tt.args.From, tt.args.To, tt.args.Amount, tt.args.Note)
 
 			if !assert.Equal(t, got, tt.want) {
 				t.Errorf("CoinUsecase.SendCoin() = %v, want %v", got, tt.want)
@@ -101,6 +95,74 @@ func TestCoinUsecase_SendCoin(t *testing.T) {
 	}
 }
 
+func TestCoinUsecase_SendCoinBatch(t *testing.T) {
+	data := entity.BatchSendCoinRequest{Transfers: []entity.SendCoinRequest{
+		{ToUser: "mary", Amount: 10, Message: " спасибо ", Category: "Thanks"},
+		{ToUser: "john", Amount: 20},
+		{ToUser: "mary", Amount: 5},
+	}}
+	names := []string{"mary", "john"}
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface)
+		want     entity.BatchSendCoinResponse
+		err      error
+	}{
+		{
+			name: "Unknown recipients",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface) {
+				userRepo.EXPECT().GetByNames(ctx, names).Return([]entity.User{{ID: 2, Name: "mary"}}, nil)
+			},
+			want: entity.BatchSendCoinResponse{},
+			err:  &myErrors.UnknownUsersError{Names: []string{"john"}},
+		},
+		{
+			name: "Err in GetByNames",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface) {
+				userRepo.EXPECT().GetByNames(ctx, names).Return([]entity.User{}, ErrDB)
+			},
+			want: entity.BatchSendCoinResponse{},
+			err:  ErrDB,
+		},
+		{
+			name: "Not enough coins",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface) {
+				userRepo.EXPECT().GetByNames(ctx, names).Return([]entity.User{{ID: 2, Name: "mary"}, {ID: 3, Name: "john"}}, nil)
+				coinRepo.EXPECT().SendCoinBatch(ctx, gomock.Any()).Return(myErrors.NotEnoughCoinErr)
+			},
+			want: entity.BatchSendCoinResponse{},
+			err:  myErrors.NotEnoughCoinErr,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface) {
+				userRepo.EXPECT().GetByNames(ctx, names).Return([]entity.User{{ID: 2, Name: "mary"}, {ID: 3, Name: "john"}}, nil)
+				coinRepo.EXPECT().SendCoinBatch(ctx, []entity.Transaction{
+					{From: 1, To: 2, Amount: 10, Message: "спасибо", Category: "thanks"},
+					{From: 1, To: 3, Amount: 20},
+					{From: 1, To: 2, Amount: 5},
+				}).Return(nil)
+			},
+			want: entity.BatchSendCoinResponse{Count: 3, Total: 35},
+			err:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			coinRepo := mock.NewMockCoinInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewCoin(coinRepo, userRepo)
+
+			tt.repoMock(context.Background(), userRepo, coinRepo)
+			got, err := usecase.SendCoinBatch(context.Background(), 1, data)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestCoinUsecase_GetCoinHistory(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -118,62 +180,95 @@ func TestCoinUsecase_GetCoinHistory(t *testing.T) {
 			id:        1,
 			wantError: true,
 			err:       ErrDB,
-			want:      entity.CoinHistory{Received: []entity.Received{}, Sent: []entity.Sent{}},
+			want:      entity.CoinHistory{Received: []entity.Received{}, Sent: []entity.Sent{}, Refunds: []entity.Refund{}},
 		},
 		{
-			name: "Err in GetUser",
+			name: "Success, but CoinHistory is empty",
 			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, id uint32) {
 				coinRepo.EXPECT().GetCoinHistory(ctx, id).
-					Return([]entity.Transaction{
-						{From: 1, To: 2, Amount: 50},
-						{From: 2, To: 1, Amount: 14},
-						{From: 1, To: 3, Amount: 100},
-					}, nil)
-				userRepo.EXPECT().GetUser(ctx, "", uint32(2)).Return(nil, ErrDB)
+					Return([]entity.Transaction{}, nil)
+				coinRepo.EXPECT().GetRefunds(ctx, id).Return([]entity.Refund{}, nil)
 			},
 			id:        1,
-			wantError: true,
-			err:       ErrDB,
-			want:      entity.CoinHistory{Received: []entity.Received{}, Sent: []entity.Sent{}},
+			wantError: false,
+			err:       nil,
+			want:      entity.CoinHistory{Received: []entity.Received{}, Sent: []entity.Sent{}, Refunds: []entity.Refund{}},
 		},
 		{
-			name: "Success, but CoinHistory is empty",
+			name: "Success",
 			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, id uint32) {
 				coinRepo.EXPECT().GetCoinHistory(ctx, id).
-					Return([]entity.Transaction{}, nil)
+					Return([]entity.Transaction{
+						{ID: 3, From: 1, To: 2, FromName: "sofia", ToName: "mary", Amount: 50},
+						{ID: 2, From: 2, To: 1, FromName: "mary", ToName: "sofia", Amount: 14, Message: "за ревью", Category: "thanks"},
+						{ID: 1, From: 1, To: 3, FromName: "sofia", ToName: "john", Amount: 20},
+					}, nil)
+				coinRepo.EXPECT().GetRefunds(ctx, id).Return([]entity.Refund{}, nil)
 			},
 			id:        1,
 			wantError: false,
 			err:       nil,
-			want:      entity.CoinHistory{Received: []entity.Received{}, Sent: []entity.Sent{}},
+			want: entity.CoinHistory{
+				Received: []entity.Received{
+					{ID: 2, FromUser: "mary", Amount: 14, Message: "за ревью", Category: "thanks"},
+				},
+				Sent: []entity.Sent{
+					{ID: 3, ToUser: "mary", Amount: 50},
+					{ID: 1, ToUser: "john", Amount: 20},
+				},
+				Refunds: []entity.Refund{},
+			},
 		},
 		{
-			name: "Success",
+			name: "Success, counterparty is deleted",
 			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, id uint32) {
 				coinRepo.EXPECT().GetCoinHistory(ctx, id).
 					Return([]entity.Transaction{
-						{From: 1, To: 2, Amount: 50},
-						{From: 2, To: 1, Amount: 14},
-						{From: 1, To: 3, Amount: 20},
+						{ID: 2, From: 1, To: 0, FromName: "sofia", ToName: "", Amount: 50},
+						{ID: 1, From: 0, To: 1, FromName: "", ToName: "sofia", Amount: 14},
 					}, nil)
-				userRepo.EXPECT().GetUser(ctx, "", uint32(2)).
-					Return(&entity.User{ID: 2, Name: "mary", Coins: 200}, nil)
-				userRepo.EXPECT().GetUser(ctx, "", uint32(2)).
-					Return(&entity.User{ID: 2, Name: "mary", Coins: 200}, nil)
-				userRepo.EXPECT().GetUser(ctx, "", uint32(3)).
-					Return(&entity.User{ID: 3, Name: "sofia", Coins: 100}, nil)
+				coinRepo.EXPECT().GetRefunds(ctx, id).Return([]entity.Refund{}, nil)
 			},
 			id:        1,
 			wantError: false,
 			err:       nil,
 			want: entity.CoinHistory{
 				Received: []entity.Received{
-					{FromUser: "mary", Amount: 14},
+					{ID: 1, FromUser: entity.DeletedUserName, Amount: 14},
 				},
 				Sent: []entity.Sent{
-					{ToUser: "mary", Amount: 50},
-					{ToUser: "sofia", Amount: 20},
+					{ID: 2, ToUser: entity.DeletedUserName, Amount: 50},
 				},
+				Refunds: []entity.Refund{},
+			},
+		},
+		{
+			name: "Err GetRefunds",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, id uint32) {
+				coinRepo.EXPECT().GetCoinHistory(ctx, id).
+					Return([]entity.Transaction{{ID: 1, From: 1, To: 2, Amount: 50}}, nil)
+				coinRepo.EXPECT().GetRefunds(ctx, id).Return([]entity.Refund{}, ErrDB)
+			},
+			id:        1,
+			wantError: true,
+			err:       ErrDB,
+			want:      entity.CoinHistory{Received: []entity.Received{}, Sent: []entity.Sent{}, Refunds: []entity.Refund{}},
+		},
+		{
+			name: "Success, with refunds",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, coinRepo *mock.MockCoinInterface, id uint32) {
+				coinRepo.EXPECT().GetCoinHistory(ctx, id).
+					Return([]entity.Transaction{}, nil)
+				coinRepo.EXPECT().GetRefunds(ctx, id).
+					Return([]entity.Refund{{ID: 4, InventoryID: 10, Amount: 80}}, nil)
+			},
+			id:        1,
+			wantError: false,
+			err:       nil,
+			want: entity.CoinHistory{
+				Received: []entity.Received{},
+				Sent:     []entity.Sent{},
+				Refunds:  []entity.Refund{{ID: 4, InventoryID: 10, Amount: 80}},
 			},
 		},
 	}
@@ -198,3 +293,113 @@ func TestCoinUsecase_GetCoinHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestCoinUsecase_GetCoinHistoryPage(t *testing.T) {
+	entries := []entity.HistoryEntry{
+		{ID: 5, Direction: entity.DirectionSent, Counterparty: "mary", Amount: 50},
+		{ID: 4, Direction: entity.DirectionReceived, Counterparty: "mary", Amount: 14},
+		{ID: 2, Direction: entity.DirectionSent, Counterparty: "sofia", Amount: 20},
+	}
+	tests := []struct {
+		name      string
+		filter    entity.CoinHistoryFilter
+		repoMock  func(ctx context.Context, coinRepo *mock.MockCoinInterface)
+		wantError bool
+		want      entity.CoinHistoryPage
+	}{
+		{
+			name:   "Err GetCoinHistoryPage",
+			filter: entity.CoinHistoryFilter{UserID: 1, Limit: 2},
+			repoMock: func(ctx context.Context, coinRepo *mock.MockCoinInterface) {
+				coinRepo.EXPECT().GetCoinHistoryPage(ctx, entity.CoinHistoryFilter{UserID: 1, Limit: 3}).
+					Return([]entity.HistoryEntry{}, ErrDB)
+			},
+			wantError: true,
+			want:      entity.CoinHistoryPage{Entries: []entity.HistoryEntry{}},
+		},
+		{
+			name:   "Success, has next page",
+			filter: entity.CoinHistoryFilter{UserID: 1, Limit: 2},
+			repoMock: func(ctx context.Context, coinRepo *mock.MockCoinInterface) {
+				coinRepo.EXPECT().GetCoinHistoryPage(ctx, entity.CoinHistoryFilter{UserID: 1, Limit: 3}).
+					Return(entries, nil)
+			},
+			wantError: false,
+			want:      entity.CoinHistoryPage{Entries: entries[:2], NextCursor: 4},
+		},
+		{
+			name:   "Success, last page with default limit",
+			filter: entity.CoinHistoryFilter{UserID: 1},
+			repoMock: func(ctx context.Context, coinRepo *mock.MockCoinInterface) {
+				coinRepo.EXPECT().GetCoinHistoryPage(ctx, entity.CoinHistoryFilter{UserID: 1, Limit: entity.DefaultHistoryLimit + 1}).
+					Return(entries, nil)
+			},
+			wantError: false,
+			want:      entity.CoinHistoryPage{Entries: entries},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			coinRepo := mock.NewMockCoinInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewCoin(coinRepo, userRepo)
+
+			tt.repoMock(context.Background(), coinRepo)
+			got, err := usecase.GetCoinHistoryPage(context.Background(), tt.filter)
+
+			if (err != nil) != tt.wantError {
+				t.Errorf("CoinUsecase.GetCoinHistoryPage() error = %v, wantErr %v", err, tt.wantError)
+				return
+			}
+			if !assert.Equal(t, got, tt.want) {
+				t.Errorf("CoinUsecase.GetCoinHistoryPage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoinUsecase_GetBalance(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, coinRepo *mock.MockCoinInterface, id uint32)
+		id       uint32
+		want     uint32
+		err      error
+	}{
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, coinRepo *mock.MockCoinInterface, id uint32) {
+				coinRepo.EXPECT().CheckBalance(ctx, id).Return(uint32(950), nil)
+			},
+			id:   1,
+			want: 950,
+			err:  nil,
+		},
+		{
+			name: "Err CheckBalance",
+			repoMock: func(ctx context.Context, coinRepo *mock.MockCoinInterface, id uint32) {
+				coinRepo.EXPECT().CheckBalance(ctx, id).Return(uint32(0), ErrDB)
+			},
+			id:   1,
+			want: 0,
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			coinRepo := mock.NewMockCoinInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewCoin(coinRepo, userRepo)
+
+			tt.repoMock(context.Background(), coinRepo, tt.id)
+			got, err := usecase.GetBalance(context.Background(), tt.id)
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
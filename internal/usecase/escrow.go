@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"context"
+	"time"
+)
+
+type EscrowInterface interface {
+	Hold(ctx context.Context, from uint32, to uint32, amount uint32, note entity.TransferNote) (entity.PendingTransfer, error)
+	List(ctx context.Context, userId uint32, direction string) ([]entity.PendingTransfer, error)
+	Accept(ctx context.Context, userId uint32, id uint32) (entity.PendingTransfer, error)
+	Reject(ctx context.Context, userId uint32, id uint32) (entity.PendingTransfer, error)
+	ReturnExpired(ctx context.Context) (int, error)
+}
+
+type Escrow struct {
+	repo repo.EscrowInterface
+	// window - сколько времени у получателя есть на решение
+	window time.Duration
+	// batchSize - сколько просроченных переводов возвращается за один запуск обработчика
+	batchSize int
+}
+
+func NewEscrow(r repo.EscrowInterface, window time.Duration, batchSize int) EscrowInterface {
+	return &Escrow{repo: r, window: window, batchSize: batchSize}
+}
+
+func (e *Escrow) Hold(ctx context.Context, from uint32, to uint32, amount uint32, note entity.TransferNote) (entity.PendingTransfer, error) {
+	// баланс проверяется в репозитории внутри транзакции
+	return e.repo.Hold(ctx, entity.PendingTransfer{
+		FromUserID: from,
+		ToUserID:   to,
+		Amount:     amount,
+		Message:    note.Message,
+		Category:   note.Category,
+		ExpiresAt:  time.Now().Add(e.window),
+	})
+}
+
+func (e *Escrow) List(ctx context.Context, userId uint32, direction string) ([]entity.PendingTransfer, error) {
+	return e.repo.List(ctx, userId, direction)
+}
+
+func (e *Escrow) Accept(ctx context.Context, userId uint32, id uint32) (entity.PendingTransfer, error) {
+	return e.repo.Resolve(ctx, userId, id, true)
+}
+
+func (e *Escrow) Reject(ctx context.Context, userId uint32, id uint32) (entity.PendingTransfer, error) {
+	return e.repo.Resolve(ctx, userId, id, false)
+}
+
+// ReturnExpired возвращает отправителям монеты непринятых вовремя переводов
+// и переводов удаленным получателям
+func (e *Escrow) ReturnExpired(ctx context.Context) (int, error) {
+	return e.repo.ReturnExpired(ctx, e.batchSize)
+}
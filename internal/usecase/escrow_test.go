@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscrowUsecase_Hold(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	escrowRepo := mock.NewMockEscrowInterface(ctl)
+	usecase := NewEscrow(escrowRepo, 72*time.Hour, 10)
+	ctx := context.Background()
+
+	escrowRepo.EXPECT().Hold(ctx, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, transfer entity.PendingTransfer) (entity.PendingTransfer, error) {
+			assert.Equal(t, uint32(1), transfer.FromUserID)
+			assert.Equal(t, uint32(2), transfer.ToUserID)
+			assert.Equal(t, uint32(100), transfer.Amount)
+			assert.Equal(t, "thanks", transfer.Category)
+			assert.WithinDuration(t, time.Now().Add(72*time.Hour), transfer.ExpiresAt, time.Minute)
+			return entity.PendingTransfer{}, myErrors.NotEnoughCoinErr
+		})
+
+	_, err := usecase.Hold(ctx, 1, 2, 100, entity.TransferNote{Category: "thanks"})
+	assert.Equal(t, myErrors.NotEnoughCoinErr, err)
+}
+
+func TestEscrowUsecase_Resolve(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	escrowRepo := mock.NewMockEscrowInterface(ctl)
+	usecase := NewEscrow(escrowRepo, 72*time.Hour, 10)
+	ctx := context.Background()
+
+	escrowRepo.EXPECT().Resolve(ctx, uint32(2), uint32(5), true).Return(entity.PendingTransfer{ID: 5, Status: entity.EscrowAccepted}, nil)
+	escrowRepo.EXPECT().Resolve(ctx, uint32(2), uint32(6), false).Return(entity.PendingTransfer{}, myErrors.NoPendingTransferErr)
+	escrowRepo.EXPECT().ReturnExpired(ctx, 10).Return(3, nil)
+
+	res, err := usecase.Accept(ctx, 2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.EscrowAccepted, res.Status)
+	_, err = usecase.Reject(ctx, 2, 6)
+	assert.Equal(t, myErrors.NoPendingTransferErr, err)
+	returned, err := usecase.ReturnExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, returned)
+}
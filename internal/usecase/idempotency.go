@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"time"
+)
+
+type IdempotencyInterface interface {
+	Start(ctx context.Context, key string, userId uint32, requestHash string) (*entity.IdempotencyRecord, error)
+	Finish(ctx context.Context, record entity.IdempotencyRecord) error
+	Cancel(ctx context.Context, key string, userId uint32) error
+}
+
+type Idempotency struct {
+	repo repo.IdempotencyInterface
+	ttl  time.Duration
+}
+
+func NewIdempotency(r repo.IdempotencyInterface, ttl time.Duration) IdempotencyInterface {
+	return &Idempotency{repo: r, ttl: ttl}
+}
+
+// Start возвращает nil, если запрос нужно выполнить, или сохраненный ответ для повтора.
+func (i *Idempotency) Start(ctx context.Context, key string, userId uint32, requestHash string) (*entity.IdempotencyRecord, error) {
+	record := entity.IdempotencyRecord{Key: key, UserID: userId, RequestHash: requestHash}
+	saved, err := i.repo.Lock(ctx, record, time.Now().Add(i.ttl))
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, nil
+	}
+	if saved.RequestHash != requestHash {
+		return nil, myErrors.IdempotencyKeyReusedErr
+	}
+	if saved.StatusCode == 0 {
+		return nil, myErrors.IdempotencyInProgressErr
+	}
+	return saved, nil
+}
+
+func (i *Idempotency) Finish(ctx context.Context, record entity.IdempotencyRecord) error {
+	return i.repo.Save(ctx, record)
+}
+
+func (i *Idempotency) Cancel(ctx context.Context, key string, userId uint32) error {
+	return i.repo.Delete(ctx, key, userId)
+}
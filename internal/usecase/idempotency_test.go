@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyUsecase_Start(t *testing.T) {
+	key := "key"
+	userId := uint32(1)
+	hash := "hash"
+	record := entity.IdempotencyRecord{Key: key, UserID: userId, RequestHash: hash}
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, idempotencyRepo *mock.MockIdempotencyInterface)
+		want     *entity.IdempotencyRecord
+		err      error
+	}{
+		{
+			name: "Err in Lock",
+			repoMock: func(ctx context.Context, idempotencyRepo *mock.MockIdempotencyInterface) {
+				idempotencyRepo.EXPECT().Lock(ctx, record, gomock.Any()).Return(nil, ErrDB)
+			},
+			want: nil,
+			err:  ErrDB,
+		},
+		{
+			name: "New key",
+			repoMock: func(ctx context.Context, idempotencyRepo *mock.MockIdempotencyInterface) {
+				idempotencyRepo.EXPECT().Lock(ctx, record, gomock.Any()).Return(nil, nil)
+			},
+			want: nil,
+			err:  nil,
+		},
+		{
+			name: "Key is used with another request",
+			repoMock: func(ctx context.Context, idempotencyRepo *mock.MockIdempotencyInterface) {
+				idempotencyRepo.EXPECT().Lock(ctx, record, gomock.Any()).
+					Return(&entity.IdempotencyRecord{Key: key, UserID: userId, RequestHash: "another", StatusCode: 200}, nil)
+			},
+			want: nil,
+			err:  myErrors.IdempotencyKeyReusedErr,
+		},
+		{
+			name: "Request is in progress",
+			repoMock: func(ctx context.Context, idempotencyRepo *mock.MockIdempotencyInterface) {
+				idempotencyRepo.EXPECT().Lock(ctx, record, gomock.Any()).
+					Return(&entity.IdempotencyRecord{Key: key, UserID: userId, RequestHash: hash}, nil)
+			},
+			want: nil,
+			err:  myErrors.IdempotencyInProgressErr,
+		},
+		{
+			name: "Replay",
+			repoMock: func(ctx context.Context, idempotencyRepo *mock.MockIdempotencyInterface) {
+				idempotencyRepo.EXPECT().Lock(ctx, record, gomock.Any()).
+					Return(&entity.IdempotencyRecord{Key: key, UserID: userId, RequestHash: hash, StatusCode: 200, Response: []byte(`"ok"`)}, nil)
+			},
+			want: &entity.IdempotencyRecord{Key: key, UserID: userId, RequestHash: hash, StatusCode: 200, Response: []byte(`"ok"`)},
+			err:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			idempotencyRepo := mock.NewMockIdempotencyInterface(ctl)
+			usecase := NewIdempotency(idempotencyRepo, time.Hour)
+
+			tt.repoMock(context.Background(), idempotencyRepo)
+			got, err := usecase.Start(context.Background(), key, userId, hash)
+
+			assert.Equal(t, tt.err, err)
+			if !assert.Equal(t, got, tt.want) {
+				t.Errorf("IdempotencyUsecase.Start() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
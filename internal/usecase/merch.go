@@ -5,11 +5,14 @@ import (
 	"avito-winter-2025/internal/repo"
 	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
+	"math"
 )
 
 type MerchInterface interface {
 	Buy(ctx context.Context, userId uint32, merchName string) error
 	GetInventoryHistory(ctx context.Context, id uint32) ([]entity.Inventory, error)
+	GetCatalog(ctx context.Context) ([]entity.CatalogItem, error)
+	CreateOrder(ctx context.Context, userId uint32, data entity.OrderRequest) (entity.Order, error)
 }
 
 type Merch struct {
@@ -29,14 +32,8 @@ func (m *Merch) Buy(ctx context.Context, userId uint32, merchName string) error
 	if merch == nil {
 		return myErrors.NoMerchErr
 	}
-	balance, err := m.coinRepo.CheckBalance(ctx, userId)
-	if err != nil {
-		return err
-	}
-	if balance < merch.Cost {
-		return myErrors.NotEnoughCoinErr
-	}
-	err = m.merchRepo.Buy(ctx, userId, merch.ID, merch.Cost)
+	// цена и баланс проверяются в репозитории внутри транзакции
+	err = m.merchRepo.Buy(ctx, userId, merch.ID)
 	if err != nil {
 		return err
 	}
@@ -50,3 +47,68 @@ func (m *Merch) GetInventoryHistory(ctx context.Context, id uint32) ([]entity.In
 	}
 	return res, nil
 }
+
+func (m *Merch) GetCatalog(ctx context.Context) ([]entity.CatalogItem, error) {
+	merch, err := m.merchRepo.ListMerch(ctx)
+	if err != nil {
+		return []entity.CatalogItem{}, err
+	}
+	res := make([]entity.CatalogItem, 0, len(merch))
+	for _, item := range merch {
+		res = append(res, entity.CatalogItem{
+			ID:        item.ID,
+			Name:      item.Name,
+			Cost:      item.Cost,
+			Stock:     item.Stock,
+			Available: item.InStock(),
+		})
+	}
+	return res, nil
+}
+
+func (m *Merch) CreateOrder(ctx context.Context, userId uint32, data entity.OrderRequest) (entity.Order, error) {
+	if !data.Valid() {
+		return entity.Order{}, myErrors.InvalidOrderErr
+	}
+	// повторяющиеся позиции объединяются в одну строку заказа
+	names := []string{}
+	quantity := map[string]uint32{}
+	for _, line := range data.Lines {
+		if _, ok := quantity[line.Item]; !ok {
+			names = append(names, line.Item)
+		}
+		quantity[line.Item] += uint32(line.Quantity)
+	}
+	merch, err := m.merchRepo.GetByNames(ctx, names)
+	if err != nil {
+		return entity.Order{}, err
+	}
+	byName := make(map[string]entity.Merch, len(merch))
+	for _, item := range merch {
+		byName[item.Name] = item
+	}
+	order := entity.Order{Lines: make([]entity.OrderItem, 0, len(names))}
+	var total uint64
+	for _, name := range names {
+		item, ok := byName[name]
+		if !ok {
+			return entity.Order{}, myErrors.NoMerchErr
+		}
+		if item.Stock != nil && *item.Stock < quantity[name] {
+			return entity.Order{}, myErrors.OutOfStockErr
+		}
+		total += uint64(item.Cost) * uint64(quantity[name])
+		order.Lines = append(order.Lines, entity.OrderItem{
+			MerchID:  item.ID,
+			Item:     name,
+			Quantity: quantity[name],
+			Cost:     item.Cost,
+		})
+	}
+	// баланс не может превышать uint32, поэтому такой заказ оплатить нельзя
+	if total > math.MaxUint32 {
+		return entity.Order{}, myErrors.NotEnoughCoinErr
+	}
+	order.Total = uint32(total)
+	return m.merchRepo.CreateOrder(ctx, userId, order)
+}
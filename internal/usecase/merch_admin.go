@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+)
+
+type MerchAdminInterface interface {
+	List(ctx context.Context) ([]entity.Merch, error)
+	Create(ctx context.Context, data entity.MerchRequest) (entity.Merch, error)
+	Update(ctx context.Context, id uint32, data entity.MerchRequest) (entity.Merch, error)
+	Archive(ctx context.Context, id uint32) (entity.Merch, error)
+}
+
+type MerchAdmin struct {
+	merchRepo repo.MerchInterface
+}
+
+func NewMerchAdmin(m repo.MerchInterface) MerchAdminInterface {
+	return &MerchAdmin{merchRepo: m}
+}
+
+func (m *MerchAdmin) List(ctx context.Context) ([]entity.Merch, error) {
+	res, err := m.merchRepo.GetAll(ctx)
+	if err != nil {
+		return []entity.Merch{}, err
+	}
+	return res, nil
+}
+
+func (m *MerchAdmin) Create(ctx context.Context, data entity.MerchRequest) (entity.Merch, error) {
+	return m.merchRepo.CreateMerch(ctx, data.Merch())
+}
+
+func (m *MerchAdmin) Update(ctx context.Context, id uint32, data entity.MerchRequest) (entity.Merch, error) {
+	merch := data.Merch()
+	merch.ID = id
+	res, err := m.merchRepo.UpdateMerch(ctx, merch)
+	if err != nil {
+		return entity.Merch{}, err
+	}
+	if res == nil {
+		return entity.Merch{}, myErrors.NoMerchErr
+	}
+	return *res, nil
+}
+
+func (m *MerchAdmin) Archive(ctx context.Context, id uint32) (entity.Merch, error) {
+	res, err := m.merchRepo.ArchiveMerch(ctx, id)
+	if err != nil {
+		return entity.Merch{}, err
+	}
+	if res == nil {
+		return entity.Merch{}, myErrors.NoMerchErr
+	}
+	return *res, nil
+}
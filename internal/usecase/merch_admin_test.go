@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerchAdminUsecase_Update(t *testing.T) {
+	data := entity.MerchRequest{Name: "pen", Cost: 15}
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, merchRepo *mock.MockMerchInterface)
+		want     entity.Merch
+		err      error
+	}{
+		{
+			name: "Err in UpdateMerch",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().UpdateMerch(ctx, entity.Merch{ID: 1, Name: "pen", Cost: 15}).Return(nil, ErrDB)
+			},
+			want: entity.Merch{},
+			err:  ErrDB,
+		},
+		{
+			name: "There are no merch with this id",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().UpdateMerch(ctx, entity.Merch{ID: 1, Name: "pen", Cost: 15}).Return(nil, nil)
+			},
+			want: entity.Merch{},
+			err:  myErrors.NoMerchErr,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().UpdateMerch(ctx, entity.Merch{ID: 1, Name: "pen", Cost: 15}).
+					Return(&entity.Merch{ID: 1, Name: "pen", Cost: 15}, nil)
+			},
+			want: entity.Merch{ID: 1, Name: "pen", Cost: 15},
+			err:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			merchRepo := mock.NewMockMerchInterface(ctl)
+			usecase := NewMerchAdmin(merchRepo)
+
+			tt.repoMock(context.Background(), merchRepo)
+			got, err := usecase.Update(context.Background(), 1, data)
+
+			assert.Equal(t, tt.err, err)
+			if !assert.Equal(t, got, tt.want) {
+				t.Errorf("MerchAdminUsecase.Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerchAdminUsecase_Archive(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, merchRepo *mock.MockMerchInterface)
+		want     entity.Merch
+		err      error
+	}{
+		{
+			name: "Err in ArchiveMerch",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().ArchiveMerch(ctx, uint32(1)).Return(nil, ErrDB)
+			},
+			want: entity.Merch{},
+			err:  ErrDB,
+		},
+		{
+			name: "There are no merch with this id",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().ArchiveMerch(ctx, uint32(1)).Return(nil, nil)
+			},
+			want: entity.Merch{},
+			err:  myErrors.NoMerchErr,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().ArchiveMerch(ctx, uint32(1)).Return(&entity.Merch{ID: 1, Name: "pen", Cost: 10}, nil)
+			},
+			want: entity.Merch{ID: 1, Name: "pen", Cost: 10},
+			err:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			merchRepo := mock.NewMockMerchInterface(ctl)
+			usecase := NewMerchAdmin(merchRepo)
+
+			tt.repoMock(context.Background(), merchRepo)
+			got, err := usecase.Archive(context.Background(), 1)
+
+			assert.Equal(t, tt.err, err)
+			if !assert.Equal(t, got, tt.want) {
+				t.Errorf("MerchAdminUsecase.Archive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
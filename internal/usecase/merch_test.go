@@ -50,25 +50,6 @@ func TestMerchUsecase_Buy(t *testing.T) {
 			},
 			want: myErrors.NoMerchErr,
 		},
-		{
-			name: "Err in CheckBalance",
-			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface, coinRepo *mock.MockCoinInterface, name string, userId, merchId, cost uint32) {
-				merchRepo.EXPECT().GetByName(ctx, name).
-					Return(&entity.Merch{
-						ID:   merchId,
-						Name: name,
-						Cost: cost,
-					}, nil)
-				coinRepo.EXPECT().CheckBalance(ctx, userId).Return(uint32(0), ErrDB)
-			},
-			args: args{
-				userId:    1,
-				merchName: "T-shirt",
-				merchId:   3,
-				merchCost: 80,
-			},
-			want: ErrDB,
-		},
 		{
 			name: "Err Not Enough money",
 			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface, coinRepo *mock.MockCoinInterface, name string, userId, merchId, cost uint32) {
@@ -78,7 +59,7 @@ func TestMerchUsecase_Buy(t *testing.T) {
 						Name: name,
 						Cost: cost,
 					}, nil)
-				coinRepo.EXPECT().CheckBalance(ctx, userId).Return(uint32(60), myErrors.NotEnoughCoinErr)
+				merchRepo.EXPECT().Buy(ctx, userId, merchId).Return(myErrors.NotEnoughCoinErr)
 			},
 			args: args{
 				userId:    1,
@@ -97,8 +78,7 @@ func TestMerchUsecase_Buy(t *testing.T) {
 						Name: name,
 						Cost: cost,
 					}, nil)
-				coinRepo.EXPECT().CheckBalance(ctx, userId).Return(uint32(100), nil)
-				merchRepo.EXPECT().Buy(ctx, userId, merchId, cost).Return(ErrDB)
+				merchRepo.EXPECT().Buy(ctx, userId, merchId).Return(ErrDB)
 			},
 			args: args{
 				userId:    1,
@@ -117,8 +97,7 @@ func TestMerchUsecase_Buy(t *testing.T) {
 						Name: name,
 						Cost: cost,
 					}, nil)
-				coinRepo.EXPECT().CheckBalance(ctx, userId).Return(uint32(100), nil)
-				merchRepo.EXPECT().Buy(ctx, userId, merchId, cost).Return(nil)
+				merchRepo.EXPECT().Buy(ctx, userId, merchId).Return(nil)
 			},
 			args: args{
 				userId:    1,
@@ -203,3 +182,163 @@ func TestMerchUsecase_GetInventoryHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestMerchUsecase_GetCatalog(t *testing.T) {
+	inStock := uint32(5)
+	outOfStock := uint32(0)
+	tests := []struct {
+		name      string
+		repoMock  func(ctx context.Context, merchRepo *mock.MockMerchInterface)
+		wantError bool
+		want      []entity.CatalogItem
+	}{
+		{
+			name: "Fail",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().ListMerch(ctx).Return(nil, ErrDB)
+			},
+			wantError: true,
+			want:      []entity.CatalogItem{},
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().ListMerch(ctx).
+					Return([]entity.Merch{
+						{ID: 1, Name: "pen", Cost: 10},
+						{ID: 2, Name: "cup", Cost: 20, Stock: &inStock},
+						{ID: 3, Name: "pink-hoody", Cost: 500, Stock: &outOfStock},
+					}, nil)
+			},
+			wantError: false,
+			want: []entity.CatalogItem{
+				{ID: 1, Name: "pen", Cost: 10, Available: true},
+				{ID: 2, Name: "cup", Cost: 20, Stock: &inStock, Available: true},
+				{ID: 3, Name: "pink-hoody", Cost: 500, Stock: &outOfStock, Available: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			coinRepo := mock.NewMockCoinInterface(ctl)
+			merchRepo := mock.NewMockMerchInterface(ctl)
+			usecase := NewMerch(merchRepo, coinRepo)
+
+			tt.repoMock(context.Background(), merchRepo)
+			got, err := usecase.GetCatalog(context.Background())
+
+			if (err != nil) != tt.wantError {
+				t.Errorf("MerchUsecase.GetCatalog() error = %v, wantErr %v", err, tt.wantError)
+				return
+			}
+			if !assert.Equal(t, got, tt.want) {
+				t.Errorf("MerchUsecase.GetCatalog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerchUsecase_CreateOrder(t *testing.T) {
+	userId := uint32(1)
+	stock := uint32(2)
+	catalog := []entity.Merch{
+		{ID: 2, Name: "pen", Cost: 10},
+		{ID: 5, Name: "socks", Cost: 10, Stock: &stock},
+	}
+	tests := []struct {
+		name     string
+		data     entity.OrderRequest
+		repoMock func(ctx context.Context, merchRepo *mock.MockMerchInterface)
+		want     entity.Order
+		err      error
+	}{
+		{
+			name:     "Empty order",
+			data:     entity.OrderRequest{},
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {},
+			want:     entity.Order{},
+			err:      myErrors.InvalidOrderErr,
+		},
+		{
+			name:     "Zero quantity",
+			data:     entity.OrderRequest{Lines: []entity.OrderLine{{Item: "pen", Quantity: 0}}},
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {},
+			want:     entity.Order{},
+			err:      myErrors.InvalidOrderErr,
+		},
+		{
+			name: "Unknown merch",
+			data: entity.OrderRequest{Lines: []entity.OrderLine{{Item: "pen", Quantity: 1}, {Item: "cup", Quantity: 1}}},
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().GetByNames(ctx, []string{"pen", "cup"}).Return(catalog[:1], nil)
+			},
+			want: entity.Order{},
+			err:  myErrors.NoMerchErr,
+		},
+		{
+			name: "Not enough stock",
+			data: entity.OrderRequest{Lines: []entity.OrderLine{{Item: "socks", Quantity: 3}}},
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().GetByNames(ctx, []string{"socks"}).Return(catalog[1:], nil)
+			},
+			want: entity.Order{},
+			err:  myErrors.OutOfStockErr,
+		},
+		{
+			name: "Err in Get merch by names",
+			data: entity.OrderRequest{Lines: []entity.OrderLine{{Item: "pen", Quantity: 1}}},
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().GetByNames(ctx, []string{"pen"}).Return([]entity.Merch{}, ErrDB)
+			},
+			want: entity.Order{},
+			err:  ErrDB,
+		},
+		{
+			name: "Success, duplicate lines are merged",
+			data: entity.OrderRequest{Lines: []entity.OrderLine{
+				{Item: "pen", Quantity: 1},
+				{Item: "socks", Quantity: 2},
+				{Item: "pen", Quantity: 3},
+			}},
+			repoMock: func(ctx context.Context, merchRepo *mock.MockMerchInterface) {
+				merchRepo.EXPECT().GetByNames(ctx, []string{"pen", "socks"}).Return(catalog, nil)
+				order := entity.Order{
+					Total: 60,
+					Lines: []entity.OrderItem{
+						{MerchID: 2, Item: "pen", Quantity: 4, Cost: 10},
+						{MerchID: 5, Item: "socks", Quantity: 2, Cost: 10},
+					},
+				}
+				created := order
+				created.ID = 7
+				merchRepo.EXPECT().CreateOrder(ctx, userId, order).Return(created, nil)
+			},
+			want: entity.Order{
+				ID:    7,
+				Total: 60,
+				Lines: []entity.OrderItem{
+					{MerchID: 2, Item: "pen", Quantity: 4, Cost: 10},
+					{MerchID: 5, Item: "socks", Quantity: 2, Cost: 10},
+				},
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			coinRepo := mock.NewMockCoinInterface(ctl)
+			merchRepo := mock.NewMockMerchInterface(ctl)
+			usecase := NewMerch(merchRepo, coinRepo)
+
+			tt.repoMock(context.Background(), merchRepo)
+			got, err := usecase.CreateOrder(context.Background(), userId, tt.data)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/token"
+	"context"
+	"time"
+)
+
+type PasswordInterface interface {
+	Change(ctx context.Context, userId uint32, data entity.ChangePasswordRequest) error
+	IssueReset(ctx context.Context, adminId uint32, userId uint32) (entity.PasswordReset, error)
+	Reset(ctx context.Context, data entity.ResetPasswordRequest) error
+}
+
+type Password struct {
+	repo repo.UserInterface
+	// resetTTL - срок действия токена сброса пароля
+	resetTTL time.Duration
+}
+
+func NewPassword(r repo.UserInterface, resetTTL time.Duration) PasswordInterface {
+	return &Password{repo: r, resetTTL: resetTTL}
+}
+
+// Change меняет пароль после проверки текущего. Все выпущенные токены пользователя отзываются.
+func (p *Password) Change(ctx context.Context, userId uint32, data entity.ChangePasswordRequest) error {
+	if !entity.StrongPassword(data.NewPassword) {
+		return myErrors.WeakPasswordErr
+	}
+	current, err := p.repo.GetPassword(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !current.IsEqual(data.OldPassword) {
+		return myErrors.WrongPasswordErr
+	}
+	var pass entity.Password
+	if err := pass.Hash(data.NewPassword); err != nil {
+		return err
+	}
+	return p.repo.SetPassword(ctx, userId, string(pass))
+}
+
+// IssueReset выдает одноразовый токен сброса пароля. Токен устроен так же, как refresh-токен:
+// клиенту отдается случайная строка, в базе хранится ее хэш.
+func (p *Password) IssueReset(ctx context.Context, adminId uint32, userId uint32) (entity.PasswordReset, error) {
+	user, err := p.repo.GetUser(ctx, "", userId)
+	if err != nil {
+		return entity.PasswordReset{}, err
+	}
+	if user == nil {
+		return entity.PasswordReset{}, myErrors.NoUserErr
+	}
+	resetToken, hash, err := token.NewRefreshToken()
+	if err != nil {
+		return entity.PasswordReset{}, err
+	}
+	expiresAt := time.Now().Add(p.resetTTL)
+	err = p.repo.CreatePasswordReset(ctx, entity.PasswordResetToken{
+		UserID:    userId,
+		TokenHash: hash,
+		CreatedBy: adminId,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return entity.PasswordReset{}, err
+	}
+	return entity.PasswordReset{Token: resetToken, ExpiresAt: expiresAt}, nil
+}
+
+func (p *Password) Reset(ctx context.Context, data entity.ResetPasswordRequest) error {
+	if !entity.StrongPassword(data.NewPassword) {
+		return myErrors.WeakPasswordErr
+	}
+	var pass entity.Password
+	if err := pass.Hash(data.NewPassword); err != nil {
+		return err
+	}
+	ok, err := p.repo.ResetPassword(ctx, token.HashRefreshToken(data.ResetToken), string(pass))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return myErrors.InvalidResetTokenErr
+	}
+	return nil
+}
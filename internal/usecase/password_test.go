@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/token"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordUsecase_Change(t *testing.T) {
+	var current entity.Password
+	if err := current.Hash("oldpass123"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		data     entity.ChangePasswordRequest
+		repoMock func(ctx context.Context, userRepo *mock.MockUserInterface)
+		err      error
+	}{
+		{
+			name:     "Weak password",
+			data:     entity.ChangePasswordRequest{OldPassword: "oldpass123", NewPassword: "short"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {},
+			err:      myErrors.WeakPasswordErr,
+		},
+		{
+			name: "Wrong old password",
+			data: entity.ChangePasswordRequest{OldPassword: "wrongpass1", NewPassword: "newpass123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetPassword(ctx, uint32(1)).Return(current, nil)
+			},
+			err: myErrors.WrongPasswordErr,
+		},
+		{
+			name: "Err in SetPassword",
+			data: entity.ChangePasswordRequest{OldPassword: "oldpass123", NewPassword: "newpass123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetPassword(ctx, uint32(1)).Return(current, nil)
+				userRepo.EXPECT().SetPassword(ctx, uint32(1), gomock.Any()).Return(ErrDB)
+			},
+			err: ErrDB,
+		},
+		{
+			name: "Success",
+			data: entity.ChangePasswordRequest{OldPassword: "oldpass123", NewPassword: "newpass123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetPassword(ctx, uint32(1)).Return(current, nil)
+				userRepo.EXPECT().SetPassword(ctx, uint32(1), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id uint32, password string) error {
+						hash := entity.Password(password)
+						assert.True(t, hash.IsEqual("newpass123"))
+						return nil
+					})
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewPassword(userRepo, time.Hour)
+
+			tt.repoMock(context.Background(), userRepo)
+			err := usecase.Change(context.Background(), 1, tt.data)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestPasswordUsecase_IssueReset(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewPassword(userRepo, time.Hour)
+	ctx := context.Background()
+
+	userRepo.EXPECT().GetUser(ctx, "", uint32(5)).Return(nil, nil)
+	_, err := usecase.IssueReset(ctx, 1, 5)
+	assert.Equal(t, myErrors.NoUserErr, err)
+
+	var saved entity.PasswordResetToken
+	userRepo.EXPECT().GetUser(ctx, "", uint32(2)).Return(&entity.User{ID: 2}, nil)
+	userRepo.EXPECT().CreatePasswordReset(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, reset entity.PasswordResetToken) error {
+			saved = reset
+			return nil
+		})
+	res, err := usecase.IssueReset(ctx, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, token.HashRefreshToken(res.Token), saved.TokenHash)
+	assert.Equal(t, uint32(2), saved.UserID)
+	assert.Equal(t, uint32(1), saved.CreatedBy)
+	assert.Equal(t, res.ExpiresAt, saved.ExpiresAt)
+}
+
+func TestPasswordUsecase_Reset(t *testing.T) {
+	hash := token.HashRefreshToken("reset")
+	tests := []struct {
+		name     string
+		data     entity.ResetPasswordRequest
+		repoMock func(ctx context.Context, userRepo *mock.MockUserInterface)
+		err      error
+	}{
+		{
+			name:     "Weak password",
+			data:     entity.ResetPasswordRequest{ResetToken: "reset", NewPassword: "password"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {},
+			err:      myErrors.WeakPasswordErr,
+		},
+		{
+			name: "Invalid token",
+			data: entity.ResetPasswordRequest{ResetToken: "reset", NewPassword: "newpass123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().ResetPassword(ctx, hash, gomock.Any()).Return(false, nil)
+			},
+			err: myErrors.InvalidResetTokenErr,
+		},
+		{
+			name: "Err in ResetPassword",
+			data: entity.ResetPasswordRequest{ResetToken: "reset", NewPassword: "newpass123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().ResetPassword(ctx, hash, gomock.Any()).Return(false, ErrDB)
+			},
+			err: ErrDB,
+		},
+		{
+			name: "Success",
+			data: entity.ResetPasswordRequest{ResetToken: "reset", NewPassword: "newpass123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().ResetPassword(ctx, hash, gomock.Any()).Return(true, nil)
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewPassword(userRepo, time.Hour)
+
+			tt.repoMock(context.Background(), userRepo)
+			err := usecase.Reset(context.Background(), tt.data)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
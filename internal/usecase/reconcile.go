@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"context"
+)
+
+type ReconcileInterface interface {
+	Run(ctx context.Context, fix bool) (entity.ReconcileReport, error)
+}
+
+type Reconcile struct {
+	repo repo.ReconcileInterface
+}
+
+func NewReconcile(r repo.ReconcileInterface) ReconcileInterface {
+	return &Reconcile{repo: r}
+}
+
+// Run находит пользователей, чей баланс или журнал расходится с историей. При fix=false
+// только формирует отчет, иначе исправляет балансы корректирующими проводками.
+func (r *Reconcile) Run(ctx context.Context, fix bool) (entity.ReconcileReport, error) {
+	checks, err := r.repo.CheckBalances(ctx)
+	if err != nil {
+		return entity.ReconcileReport{}, err
+	}
+	res := entity.ReconcileReport{
+		Checked:    len(checks),
+		DryRun:     !fix,
+		Mismatches: []entity.BalanceMismatch{},
+	}
+	for _, check := range checks {
+		if check.Actual == check.Expected && check.Ledger == check.Expected {
+			continue
+		}
+		mismatch := entity.BalanceMismatch{
+			UserID:   check.UserID,
+			Name:     check.Name,
+			Actual:   check.Actual,
+			Expected: check.Expected,
+			Ledger:   check.Ledger,
+			Diff:     check.Expected - check.Actual,
+		}
+		// отрицательный баланс выставить нельзя, такие расхождения разбираются вручную
+		if fix && check.Expected >= 0 {
+			mismatch.Fixed, err = r.repo.Adjust(ctx, check)
+			if err != nil {
+				return entity.ReconcileReport{}, err
+			}
+		}
+		res.Mismatches = append(res.Mismatches, mismatch)
+	}
+	return res, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileUsecase_Run(t *testing.T) {
+	checks := []entity.BalanceCheck{
+		{UserID: 1, Name: "sofia", Actual: 900, Expected: 900, Ledger: 900},
+		{UserID: 2, Name: "mary", Actual: 1100, Expected: 1000, Ledger: 1100},
+		{UserID: 3, Name: "anna", Actual: 0, Expected: -50, Ledger: 0},
+		{UserID: 4, Name: "john", Actual: 1000, Expected: 1000, Ledger: -200},
+	}
+	tests := []struct {
+		name     string
+		fix      bool
+		repoMock func(ctx context.Context, reconcileRepo *mock.MockReconcileInterface)
+		want     entity.ReconcileReport
+		err      error
+	}{
+		{
+			name: "Dry run",
+			fix:  false,
+			repoMock: func(ctx context.Context, reconcileRepo *mock.MockReconcileInterface) {
+				reconcileRepo.EXPECT().CheckBalances(ctx).Return(checks, nil)
+			},
+			want: entity.ReconcileReport{
+				Checked: 4,
+				DryRun:  true,
+				Mismatches: []entity.BalanceMismatch{
+					{UserID: 2, Name: "mary", Actual: 1100, Expected: 1000, Ledger: 1100, Diff: -100},
+					{UserID: 3, Name: "anna", Actual: 0, Expected: -50, Ledger: 0, Diff: -50},
+					{UserID: 4, Name: "john", Actual: 1000, Expected: 1000, Ledger: -200, Diff: 0},
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "Fix, negative expected balance is skipped",
+			fix:  true,
+			repoMock: func(ctx context.Context, reconcileRepo *mock.MockReconcileInterface) {
+				reconcileRepo.EXPECT().CheckBalances(ctx).Return(checks, nil)
+				reconcileRepo.EXPECT().Adjust(ctx, checks[1]).Return(true, nil)
+				reconcileRepo.EXPECT().Adjust(ctx, checks[3]).Return(true, nil)
+			},
+			want: entity.ReconcileReport{
+				Checked: 4,
+				DryRun:  false,
+				Mismatches: []entity.BalanceMismatch{
+					{UserID: 2, Name: "mary", Actual: 1100, Expected: 1000, Ledger: 1100, Diff: -100, Fixed: true},
+					{UserID: 3, Name: "anna", Actual: 0, Expected: -50, Ledger: 0, Diff: -50},
+					{UserID: 4, Name: "john", Actual: 1000, Expected: 1000, Ledger: -200, Diff: 0, Fixed: true},
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "Err in Adjust",
+			fix:  true,
+			repoMock: func(ctx context.Context, reconcileRepo *mock.MockReconcileInterface) {
+				reconcileRepo.EXPECT().CheckBalances(ctx).Return(checks, nil)
+				reconcileRepo.EXPECT().Adjust(ctx, checks[1]).Return(false, ErrDB)
+			},
+			want: entity.ReconcileReport{},
+			err:  ErrDB,
+		},
+		{
+			name: "Err in CheckBalances",
+			fix:  false,
+			repoMock: func(ctx context.Context, reconcileRepo *mock.MockReconcileInterface) {
+				reconcileRepo.EXPECT().CheckBalances(ctx).Return([]entity.BalanceCheck{}, ErrDB)
+			},
+			want: entity.ReconcileReport{},
+			err:  ErrDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			reconcileRepo := mock.NewMockReconcileInterface(ctl)
+			usecase := NewReconcile(reconcileRepo)
+
+			tt.repoMock(context.Background(), reconcileRepo)
+			got, err := usecase.Run(context.Background(), tt.fix)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"context"
+	"time"
+)
+
+type RefundInterface interface {
+	Return(ctx context.Context, userId uint32, inventoryId uint32) (entity.Refund, error)
+	ListInventory(ctx context.Context, userId uint32) ([]entity.InventoryItem, error)
+}
+
+type Refund struct {
+	repo   repo.RefundInterface
+	window time.Duration
+}
+
+func NewRefund(r repo.RefundInterface, window time.Duration) RefundInterface {
+	return &Refund{repo: r, window: window}
+}
+
+// Return оформляет возврат, если с момента покупки прошло не больше window
+func (r *Refund) Return(ctx context.Context, userId uint32, inventoryId uint32) (entity.Refund, error) {
+	return r.repo.Return(ctx, userId, inventoryId, time.Now().Add(-r.window))
+}
+
+func (r *Refund) ListInventory(ctx context.Context, userId uint32) ([]entity.InventoryItem, error) {
+	return r.repo.ListInventory(ctx, userId)
+}
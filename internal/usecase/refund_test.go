@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundUsecase_Return(t *testing.T) {
+	userId := uint32(1)
+	inventoryId := uint32(10)
+	window := 24 * time.Hour
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, refundRepo *mock.MockRefundInterface)
+		want     entity.Refund
+		err      error
+	}{
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, refundRepo *mock.MockRefundInterface) {
+				refundRepo.EXPECT().Return(ctx, userId, inventoryId, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, userId, inventoryId uint32, notBefore time.Time) (entity.Refund, error) {
+						// граница окна возврата отсчитывается от текущего момента
+						assert.WithinDuration(t, time.Now().Add(-window), notBefore, time.Minute)
+						return entity.Refund{ID: 3, InventoryID: inventoryId, Amount: 80}, nil
+					})
+			},
+			want: entity.Refund{ID: 3, InventoryID: inventoryId, Amount: 80},
+			err:  nil,
+		},
+		{
+			name: "Window expired",
+			repoMock: func(ctx context.Context, refundRepo *mock.MockRefundInterface) {
+				refundRepo.EXPECT().Return(ctx, userId, inventoryId, gomock.Any()).
+					Return(entity.Refund{}, myErrors.ReturnWindowExpiredErr)
+			},
+			want: entity.Refund{},
+			err:  myErrors.ReturnWindowExpiredErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			refundRepo := mock.NewMockRefundInterface(ctl)
+			usecase := NewRefund(refundRepo, window)
+
+			tt.repoMock(context.Background(), refundRepo)
+			got, err := usecase.Return(context.Background(), userId, inventoryId)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
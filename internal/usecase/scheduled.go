@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"errors"
+	"time"
+)
+
+type ScheduledInterface interface {
+	Create(ctx context.Context, userId uint32, data entity.ScheduledTransferRequest) (entity.ScheduledTransfer, error)
+	List(ctx context.Context, userId uint32) ([]entity.ScheduledTransfer, error)
+	Update(ctx context.Context, userId uint32, id uint32, data entity.ScheduledTransferRequest) (entity.ScheduledTransfer, error)
+	Cancel(ctx context.Context, userId uint32, id uint32) error
+	RunDue(ctx context.Context) (int, error)
+}
+
+type Scheduled struct {
+	repo     repo.ScheduledInterface
+	userRepo repo.UserInterface
+	// batchSize - сколько переводов забирается за один запуск обработчика
+	batchSize int
+}
+
+func NewScheduled(r repo.ScheduledInterface, u repo.UserInterface, batchSize int) ScheduledInterface {
+	return &Scheduled{repo: r, userRepo: u, batchSize: batchSize}
+}
+
+func (s *Scheduled) Create(ctx context.Context, userId uint32, data entity.ScheduledTransferRequest) (entity.ScheduledTransfer, error) {
+	transfer, err := s.build(ctx, userId, data)
+	if err != nil {
+		return entity.ScheduledTransfer{}, err
+	}
+	return s.repo.Create(ctx, transfer)
+}
+
+func (s *Scheduled) List(ctx context.Context, userId uint32) ([]entity.ScheduledTransfer, error) {
+	return s.repo.List(ctx, userId)
+}
+
+func (s *Scheduled) Update(ctx context.Context, userId uint32, id uint32, data entity.ScheduledTransferRequest) (entity.ScheduledTransfer, error) {
+	transfer, err := s.build(ctx, userId, data)
+	if err != nil {
+		return entity.ScheduledTransfer{}, err
+	}
+	transfer.ID = id
+	// без нового startAt расписание продолжается с прежнего следующего запуска
+	if data.StartAt == nil {
+		transfer.NextRunAt = time.Time{}
+	}
+	if data.Paused {
+		transfer.Status = entity.SchedulePaused
+	}
+	return s.repo.Update(ctx, transfer)
+}
+
+func (s *Scheduled) Cancel(ctx context.Context, userId uint32, id uint32) error {
+	return s.repo.Cancel(ctx, userId, id)
+}
+
+func (s *Scheduled) build(ctx context.Context, userId uint32, data entity.ScheduledTransferRequest) (entity.ScheduledTransfer, error) {
+	to, err := s.userRepo.GetUser(ctx, data.ToUser, 0)
+	if err != nil {
+		return entity.ScheduledTransfer{}, err
+	}
+	if to == nil {
+		return entity.ScheduledTransfer{}, myErrors.NoUserErr
+	}
+	if to.ID == userId {
+		return entity.ScheduledTransfer{}, myErrors.InvalidScheduleErr
+	}
+	nextRun := time.Now()
+	if data.StartAt != nil {
+		nextRun = *data.StartAt
+	}
+	note := data.Note()
+	return entity.ScheduledTransfer{
+		FromUser:  userId,
+		ToUserID:  to.ID,
+		ToUser:    to.Name,
+		Amount:    uint32(data.Amount),
+		Message:   note.Message,
+		Category:  note.Category,
+		Interval:  data.Interval,
+		NextRunAt: nextRun,
+		Status:    entity.ScheduleActive,
+	}, nil
+}
+
+// RunDue выполняет до batchSize наступивших переводов, каждый в отдельной транзакции
+// вместе с переносом расписания. Запуск, откатившийся из-за ошибки базы, пропускается
+// до следующего вызова, а остальные переводы порции продолжают выполняться.
+func (s *Scheduled) RunDue(ctx context.Context) (int, error) {
+	processed := 0
+	failed := []uint32{}
+	errs := []error{}
+	for processed+len(failed) < s.batchSize {
+		transfer, ok, err := s.repo.RunNext(ctx, failed)
+		if err != nil {
+			errs = append(errs, err)
+			// перевод не удалось даже захватить
+			if transfer.ID == 0 {
+				break
+			}
+			failed = append(failed, transfer.ID)
+			continue
+		}
+		if !ok {
+			break
+		}
+		processed++
+	}
+	return processed, errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledUsecase_Create(t *testing.T) {
+	startAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	data := entity.ScheduledTransferRequest{
+		ToUser:   "mary",
+		Amount:   10,
+		Message:  " спасибо за неделю ",
+		Category: "Thanks",
+		StartAt:  &startAt,
+		Interval: entity.Interval(7 * 24 * time.Hour),
+	}
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, scheduledRepo *mock.MockScheduledInterface, userRepo *mock.MockUserInterface)
+		err      error
+	}{
+		{
+			name: "No recipient",
+			repoMock: func(ctx context.Context, scheduledRepo *mock.MockScheduledInterface, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(nil, nil)
+			},
+			err: myErrors.NoUserErr,
+		},
+		{
+			name: "Transfer to self",
+			repoMock: func(ctx context.Context, scheduledRepo *mock.MockScheduledInterface, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(&entity.User{ID: 1, Name: "mary"}, nil)
+			},
+			err: myErrors.InvalidScheduleErr,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, scheduledRepo *mock.MockScheduledInterface, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(&entity.User{ID: 2, Name: "mary"}, nil)
+				scheduledRepo.EXPECT().Create(ctx, entity.ScheduledTransfer{
+					FromUser:  1,
+					ToUserID:  2,
+					ToUser:    "mary",
+					Amount:    10,
+					Message:   "спасибо за неделю",
+					Category:  "thanks",
+					Interval:  entity.Interval(7 * 24 * time.Hour),
+					NextRunAt: startAt,
+					Status:    entity.ScheduleActive,
+				}).Return(entity.ScheduledTransfer{ID: 5}, nil)
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduledRepo := mock.NewMockScheduledInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewScheduled(scheduledRepo, userRepo, 10)
+
+			tt.repoMock(context.Background(), scheduledRepo, userRepo)
+			_, err := usecase.Create(context.Background(), 1, data)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestScheduledUsecase_Update(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	scheduledRepo := mock.NewMockScheduledInterface(ctl)
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewScheduled(scheduledRepo, userRepo, 10)
+	ctx := context.Background()
+
+	// без startAt время следующего запуска не меняется
+	userRepo.EXPECT().GetUser(ctx, "mary", uint32(0)).Return(&entity.User{ID: 2, Name: "mary"}, nil)
+	scheduledRepo.EXPECT().Update(ctx, entity.ScheduledTransfer{
+		ID:       5,
+		FromUser: 1,
+		ToUserID: 2,
+		ToUser:   "mary",
+		Amount:   20,
+		Interval: entity.Interval(24 * time.Hour),
+		Status:   entity.SchedulePaused,
+	}).Return(entity.ScheduledTransfer{}, myErrors.NoScheduledTransferErr)
+
+	_, err := usecase.Update(ctx, 1, 5, entity.ScheduledTransferRequest{
+		ToUser: "mary", Amount: 20, Interval: entity.Interval(24 * time.Hour), Paused: true,
+	})
+	assert.Equal(t, myErrors.NoScheduledTransferErr, err)
+}
+
+func TestScheduledUsecase_RunDue(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	scheduledRepo := mock.NewMockScheduledInterface(ctl)
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewScheduled(scheduledRepo, userRepo, 10)
+	ctx := context.Background()
+
+	// откатившийся запуск пропускается, остальные переводы порции выполняются
+	gomock.InOrder(
+		scheduledRepo.EXPECT().RunNext(ctx, []uint32{}).
+			Return(entity.ScheduledTransfer{ID: 1}, true, nil),
+		scheduledRepo.EXPECT().RunNext(ctx, []uint32{}).
+			Return(entity.ScheduledTransfer{ID: 2}, false, ErrDB),
+		scheduledRepo.EXPECT().RunNext(ctx, []uint32{2}).
+			Return(entity.ScheduledTransfer{ID: 3, LastError: myErrors.NotEnoughCoinErr.Error()}, true, nil),
+		scheduledRepo.EXPECT().RunNext(ctx, []uint32{2}).
+			Return(entity.ScheduledTransfer{}, false, nil),
+	)
+	processed, err := usecase.RunDue(ctx)
+	assert.ErrorIs(t, err, ErrDB)
+	assert.Equal(t, 2, processed)
+
+	// порция ограничена batchSize
+	scheduledRepo.EXPECT().RunNext(ctx, []uint32{}).
+		Return(entity.ScheduledTransfer{ID: 1}, true, nil).Times(10)
+	processed, err = usecase.RunDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, processed)
+
+	scheduledRepo.EXPECT().RunNext(ctx, []uint32{}).Return(entity.ScheduledTransfer{}, false, ErrDB)
+	_, err = usecase.RunDue(ctx)
+	assert.ErrorIs(t, err, ErrDB)
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/token"
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type TokenInterface interface {
+	Issue(ctx context.Context, user entity.User) (entity.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (entity.TokenPair, error)
+	Logout(ctx context.Context, userId uint32, access entity.AccessToken, refreshToken string) error
+	IsRevoked(ctx context.Context, access entity.AccessToken) (bool, error)
+}
+
+type Token struct {
+	repo       repo.TokenInterface
+	userRepo   repo.UserInterface
+	jwt        token.JWT
+	refreshTTL time.Duration
+	// syncInterval - как часто кэш отозванных токенов дополняется из базы
+	syncInterval time.Duration
+	// syncGroup объединяет одновременные запросы к базе в один
+	syncGroup singleflight.Group
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	// cutoffs - время смены пароля по пользователям
+	cutoffs  map[uint32]time.Time
+	syncedAt time.Time
+}
+
+func NewToken(r repo.TokenInterface, u repo.UserInterface, j token.JWT, refreshTTL time.Duration, syncInterval time.Duration) TokenInterface {
+	return &Token{
+		repo:         r,
+		userRepo:     u,
+		jwt:          j,
+		refreshTTL:   refreshTTL,
+		syncInterval: syncInterval,
+		revoked:      map[string]time.Time{},
+		cutoffs:      map[uint32]time.Time{},
+	}
+}
+
+// Issue выпускает access-токен и refresh-токен нового семейства
+func (t *Token) Issue(ctx context.Context, user entity.User) (entity.TokenPair, error) {
+	family, err := token.NewTokenID()
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	refresh, hash, err := token.NewRefreshToken()
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	err = t.repo.CreateRefresh(ctx, entity.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		Family:    family,
+		ExpiresAt: time.Now().Add(t.refreshTTL),
+	})
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	access, err := t.jwt.GenerateToken(user.ID, user.Name, user.Role)
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	return entity.TokenPair{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару. Повторное использование уже
+// обмененного токена означает его утечку, поэтому отзывается все семейство.
+func (t *Token) Refresh(ctx context.Context, refreshToken string) (entity.TokenPair, error) {
+	old, err := t.repo.GetRefresh(ctx, token.HashRefreshToken(refreshToken))
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	if old == nil || old.ExpiresAt.Before(time.Now()) {
+		return entity.TokenPair{}, myErrors.InvalidRefreshTokenErr
+	}
+	if old.RevokedAt != nil {
+		if err := t.repo.RevokeFamily(ctx, old.Family); err != nil {
+			return entity.TokenPair{}, err
+		}
+		return entity.TokenPair{}, myErrors.InvalidRefreshTokenErr
+	}
+	user, err := t.userRepo.GetUser(ctx, "", old.UserID)
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	if user == nil {
+		return entity.TokenPair{}, myErrors.InvalidRefreshTokenErr
+	}
+	refresh, hash, err := token.NewRefreshToken()
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	ok, err := t.repo.RotateRefresh(ctx, old.ID, entity.RefreshToken{
+		UserID:    old.UserID,
+		TokenHash: hash,
+		Family:    old.Family,
+		ExpiresAt: time.Now().Add(t.refreshTTL),
+	})
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	if !ok {
+		if err := t.repo.RevokeFamily(ctx, old.Family); err != nil {
+			return entity.TokenPair{}, err
+		}
+		return entity.TokenPair{}, myErrors.InvalidRefreshTokenErr
+	}
+	access, err := t.jwt.GenerateToken(user.ID, user.Name, user.Role)
+	if err != nil {
+		return entity.TokenPair{}, err
+	}
+	return entity.TokenPair{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+// Logout отзывает текущий access-токен и, если передан, refresh-токен со всем семейством
+func (t *Token) Logout(ctx context.Context, userId uint32, access entity.AccessToken, refreshToken string) error {
+	if err := t.repo.RevokeAccess(ctx, access); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.revoked[access.ID] = access.ExpiresAt
+	t.mu.Unlock()
+	if refreshToken == "" {
+		return nil
+	}
+	refresh, err := t.repo.GetRefresh(ctx, token.HashRefreshToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	// чужой refresh-токен отзывать нельзя
+	if refresh == nil || refresh.UserID != userId {
+		return nil
+	}
+	return t.repo.RevokeFamily(ctx, refresh.Family)
+}
+
+// IsRevoked проверяет токен по кэшу в памяти: отозван ли его jti и не выпущен ли он
+// до смены пароля. Кэш дополняется из базы не чаще раза в syncInterval, поэтому токены,
+// отозванные на другом экземпляре сервиса, перестают приниматься с задержкой не больше syncInterval.
+func (t *Token) IsRevoked(ctx context.Context, access entity.AccessToken) (bool, error) {
+	t.mu.Lock()
+	stale := time.Since(t.syncedAt) >= t.syncInterval
+	t.mu.Unlock()
+	if stale {
+		// запросы к базе идут без блокировки, чтобы не задерживать проверки остальных токенов
+		if _, err, _ := t.syncGroup.Do("sync", func() (interface{}, error) {
+			return nil, t.sync(ctx)
+		}); err != nil {
+			return false, err
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if access.ID != "" {
+		if _, ok := t.revoked[access.ID]; ok {
+			return true, nil
+		}
+	}
+	// iat хранится с точностью до секунды, поэтому токены, выпущенные в ту же секунду,
+	// что и смена пароля, не отличить от выпущенных до нее: они тоже недействительны
+	if cutoff, ok := t.cutoffs[access.UserID]; ok && !access.IssuedAt.After(cutoff.Truncate(time.Second)) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// sync загружает изменения из базы и под блокировкой добавляет их в кэш
+func (t *Token) sync(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	if now.Sub(t.syncedAt) < t.syncInterval {
+		// кэш уже обновил предыдущий вызов
+		t.mu.Unlock()
+		return nil
+	}
+	// окно запроса перекрывается с предыдущим, чтобы не потерять записи,
+	// закоммиченные с опозданием
+	since := t.syncedAt.Add(-t.syncInterval)
+	t.mu.Unlock()
+
+	revoked, err := t.repo.ListRevoked(ctx, since)
+	if err != nil {
+		return err
+	}
+	cutoffs, err := t.repo.ListCutoffs(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, r := range revoked {
+		t.revoked[r.ID] = r.ExpiresAt
+	}
+	for _, c := range cutoffs {
+		t.cutoffs[c.UserID] = c.NotBefore
+	}
+	for id, expiresAt := range t.revoked {
+		if expiresAt.Before(now) {
+			delete(t.revoked, id)
+		}
+	}
+	// после истечения срока access-токена выпущенных до смены пароля токенов не осталось
+	for id, notBefore := range t.cutoffs {
+		if notBefore.Add(t.jwt.ExpTime).Before(now) {
+			delete(t.cutoffs, id)
+		}
+	}
+	t.syncedAt = now
+	return nil
+}
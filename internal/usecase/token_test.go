@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo/mock"
+	myErrors "avito-winter-2025/internal/utils/errors"
+	"avito-winter-2025/internal/utils/token"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testJWT = token.JWT{Secret: []byte("secret"), ExpTime: 15 * time.Minute}
+
+func TestTokenUsecase_Issue(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	tokenRepo := mock.NewMockTokenInterface(ctl)
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewToken(tokenRepo, userRepo, testJWT, time.Hour, time.Second)
+	user := entity.User{ID: 1, Name: "sofia", Role: entity.RoleEmployee}
+
+	var saved entity.RefreshToken
+	tokenRepo.EXPECT().CreateRefresh(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, refresh entity.RefreshToken) error {
+			saved = refresh
+			return nil
+		})
+	pair, err := usecase.Issue(context.Background(), user)
+	assert.NoError(t, err)
+
+	// в базе хранится только хэш refresh-токена
+	assert.Equal(t, token.HashRefreshToken(pair.RefreshToken), saved.TokenHash)
+	assert.Equal(t, user.ID, saved.UserID)
+	assert.NotEmpty(t, saved.Family)
+	claims, err := testJWT.ParseToken(pair.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+}
+
+func TestTokenUsecase_Refresh(t *testing.T) {
+	raw := "refresh"
+	hash := token.HashRefreshToken(raw)
+	user := &entity.User{ID: 1, Name: "sofia", Role: entity.RoleEmployee}
+	active := &entity.RefreshToken{ID: 5, UserID: 1, TokenHash: hash, Family: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	revokedAt := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface)
+		err      error
+	}{
+		{
+			name: "Unknown token",
+			repoMock: func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface) {
+				tokenRepo.EXPECT().GetRefresh(ctx, hash).Return(nil, nil)
+			},
+			err: myErrors.InvalidRefreshTokenErr,
+		},
+		{
+			name: "Expired token",
+			repoMock: func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface) {
+				expired := *active
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				tokenRepo.EXPECT().GetRefresh(ctx, hash).Return(&expired, nil)
+			},
+			err: myErrors.InvalidRefreshTokenErr,
+		},
+		{
+			name: "Reused token revokes family",
+			repoMock: func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface) {
+				reused := *active
+				reused.RevokedAt = &revokedAt
+				tokenRepo.EXPECT().GetRefresh(ctx, hash).Return(&reused, nil)
+				tokenRepo.EXPECT().RevokeFamily(ctx, active.Family).Return(nil)
+			},
+			err: myErrors.InvalidRefreshTokenErr,
+		},
+		{
+			name: "Concurrent rotation revokes family",
+			repoMock: func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface) {
+				tokenRepo.EXPECT().GetRefresh(ctx, hash).Return(active, nil)
+				userRepo.EXPECT().GetUser(ctx, "", active.UserID).Return(user, nil)
+				tokenRepo.EXPECT().RotateRefresh(ctx, active.ID, gomock.Any()).Return(false, nil)
+				tokenRepo.EXPECT().RevokeFamily(ctx, active.Family).Return(nil)
+			},
+			err: myErrors.InvalidRefreshTokenErr,
+		},
+		{
+			name: "Err in RotateRefresh",
+			repoMock: func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface) {
+				tokenRepo.EXPECT().GetRefresh(ctx, hash).Return(active, nil)
+				userRepo.EXPECT().GetUser(ctx, "", active.UserID).Return(user, nil)
+				tokenRepo.EXPECT().RotateRefresh(ctx, active.ID, gomock.Any()).Return(false, ErrDB)
+			},
+			err: ErrDB,
+		},
+		{
+			name: "Success",
+			repoMock: func(ctx context.Context, tokenRepo *mock.MockTokenInterface, userRepo *mock.MockUserInterface) {
+				tokenRepo.EXPECT().GetRefresh(ctx, hash).Return(active, nil)
+				userRepo.EXPECT().GetUser(ctx, "", active.UserID).Return(user, nil)
+				tokenRepo.EXPECT().RotateRefresh(ctx, active.ID, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, oldId uint32, next entity.RefreshToken) (bool, error) {
+						assert.Equal(t, active.Family, next.Family)
+						assert.NotEqual(t, active.TokenHash, next.TokenHash)
+						return true, nil
+					})
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			tokenRepo := mock.NewMockTokenInterface(ctl)
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewToken(tokenRepo, userRepo, testJWT, time.Hour, time.Second)
+
+			tt.repoMock(context.Background(), tokenRepo, userRepo)
+			pair, err := usecase.Refresh(context.Background(), raw)
+
+			assert.Equal(t, tt.err, err)
+			if tt.err == nil {
+				assert.NotEmpty(t, pair.AccessToken)
+				assert.NotEqual(t, raw, pair.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestTokenUsecase_Logout(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	tokenRepo := mock.NewMockTokenInterface(ctl)
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewToken(tokenRepo, userRepo, testJWT, time.Hour, time.Hour)
+	ctx := context.Background()
+	access := entity.AccessToken{ID: "jti", ExpiresAt: time.Now().Add(time.Minute)}
+
+	tokenRepo.EXPECT().RevokeAccess(ctx, access).Return(nil)
+	tokenRepo.EXPECT().GetRefresh(ctx, token.HashRefreshToken("refresh")).
+		Return(&entity.RefreshToken{ID: 5, UserID: 1, Family: "family"}, nil)
+	tokenRepo.EXPECT().RevokeFamily(ctx, "family").Return(nil)
+	// первая проверка подтягивает кэш из базы, дальше запросов нет
+	tokenRepo.EXPECT().ListRevoked(ctx, gomock.Any()).Return([]entity.RevokedToken{}, nil).Times(1)
+	tokenRepo.EXPECT().ListCutoffs(ctx, gomock.Any()).Return([]entity.TokenCutoff{}, nil).Times(1)
+
+	err := usecase.Logout(ctx, 1, access, "refresh")
+	assert.NoError(t, err)
+
+	revoked, err := usecase.IsRevoked(ctx, access)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = usecase.IsRevoked(ctx, entity.AccessToken{ID: "another"})
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenUsecase_IsRevoked(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	tokenRepo := mock.NewMockTokenInterface(ctl)
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewToken(tokenRepo, userRepo, testJWT, time.Hour, time.Hour)
+	ctx := context.Background()
+
+	tokenRepo.EXPECT().ListRevoked(ctx, gomock.Any()).
+		Return([]entity.RevokedToken{
+			{ID: "revoked", ExpiresAt: time.Now().Add(time.Minute)},
+			{ID: "expired", ExpiresAt: time.Now().Add(-time.Minute)},
+		}, nil).Times(1)
+	cutoff := time.Now().Add(-time.Minute)
+	tokenRepo.EXPECT().ListCutoffs(ctx, gomock.Any()).
+		Return([]entity.TokenCutoff{
+			{UserID: 1, NotBefore: cutoff},
+			{UserID: 2, NotBefore: time.Now().Add(-2 * time.Hour)},
+		}, nil).Times(1)
+
+	for i := 0; i < 10; i++ {
+		revoked, err := usecase.IsRevoked(ctx, entity.AccessToken{ID: "revoked"})
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	}
+	// истекшие записи вычищаются из кэша
+	revoked, err := usecase.IsRevoked(ctx, entity.AccessToken{ID: "expired"})
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// токены, выпущенные до смены пароля, недействительны
+	revoked, err = usecase.IsRevoked(ctx, entity.AccessToken{ID: "old", UserID: 1, IssuedAt: cutoff.Add(-time.Hour)})
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	// в iat нет долей секунды: токен той же секунды мог быть выпущен до смены
+	revoked, err = usecase.IsRevoked(ctx, entity.AccessToken{ID: "same-second", UserID: 1, IssuedAt: cutoff.Truncate(time.Second)})
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = usecase.IsRevoked(ctx, entity.AccessToken{ID: "new", UserID: 1, IssuedAt: cutoff.Truncate(time.Second).Add(time.Second)})
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = usecase.IsRevoked(ctx, entity.AccessToken{ID: "other", UserID: 2, IssuedAt: time.Now().Add(-3 * time.Hour)})
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestTokenUsecase_IsRevokedConcurrent(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	tokenRepo := mock.NewMockTokenInterface(ctl)
+	userRepo := mock.NewMockUserInterface(ctl)
+	usecase := NewToken(tokenRepo, userRepo, testJWT, time.Hour, time.Hour)
+	ctx := context.Background()
+	access := entity.AccessToken{ID: "jti", ExpiresAt: time.Now().Add(time.Minute)}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	// одновременные проверки делают один запрос к базе
+	tokenRepo.EXPECT().ListRevoked(ctx, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, since time.Time) ([]entity.RevokedToken, error) {
+			close(started)
+			<-release
+			return []entity.RevokedToken{}, nil
+		}).Times(1)
+	tokenRepo.EXPECT().ListCutoffs(ctx, gomock.Any()).Return([]entity.TokenCutoff{}, nil).Times(1)
+	tokenRepo.EXPECT().RevokeAccess(ctx, access).Return(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := usecase.IsRevoked(ctx, access)
+			assert.NoError(t, err)
+		}()
+	}
+	<-started
+	// пока идет запрос к базе, кэш не заблокирован
+	assert.NoError(t, usecase.Logout(ctx, 1, access, ""))
+	close(release)
+	wg.Wait()
+
+	revoked, err := usecase.IsRevoked(ctx, access)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
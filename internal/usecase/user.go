@@ -5,48 +5,149 @@ import (
 	"avito-winter-2025/internal/repo"
 	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
+	"time"
 )
 
+// dummyPassword - bcrypt-хеш с той же стоимостью, что и у паролей пользователей.
+// С ним сравнивается пароль при входе под неизвестным именем, чтобы по времени
+// ответа нельзя было отличить несуществующее имя от неверного пароля
+var dummyPassword = entity.Password("$2a$10$GzkCL6koUAP5CN7jubgQ9OoZFLelS0t12INqgDv3DIRcss8PbksE.")
+
+// checkPassword сравнивает пароль с хешем; подменяется в тестах
+var checkPassword = func(hash entity.Password, password string) bool {
+	return hash.IsEqual(password)
+}
+
 type UserInterface interface {
-	Auth(ctx context.Context, data entity.AuthRequest) (entity.User, error)
+	Auth(ctx context.Context, data entity.AuthRequest, clientIP string) (entity.User, error)
 	GetUser(ctx context.Context, name string, id uint32) (entity.User, error)
+	Register(ctx context.Context, data entity.AuthRequest) (entity.User, error)
 }
 
 type User struct {
-	repo repo.UserInterface
+	repo     repo.UserInterface
+	attempts repo.LoginAttemptInterface
+	policy   entity.LockoutPolicy
+	// autoRegister включает создание пользователя при первом входе, как в исходной спецификации
+	autoRegister bool
 }
 
-func NewUser(r repo.UserInterface) UserInterface {
-	return &User{repo: r}
+func NewUser(r repo.UserInterface, a repo.LoginAttemptInterface, policy entity.LockoutPolicy, autoRegister bool) UserInterface {
+	return &User{repo: r, attempts: a, policy: policy, autoRegister: autoRegister}
 }
 
-func (u *User) Auth(ctx context.Context, data entity.AuthRequest) (entity.User, error) {
+func (u *User) Auth(ctx context.Context, data entity.AuthRequest, clientIP string) (entity.User, error) {
+	userKey, ipKey := entity.LoginKeyUser(data.Name), entity.LoginKeyIP(clientIP)
+	if err := u.checkAttempts(ctx, userKey, ipKey); err != nil {
+		return entity.User{}, err
+	}
 	user, err := u.repo.GetUser(ctx, data.Name, 0)
 	if err != nil {
 		return entity.User{}, err
 	}
 	if user == nil {
-		var pass entity.Password
-		err := pass.Hash(data.Password)
-		if err != nil {
-			return entity.User{}, err
+		if u.autoRegister {
+			return u.createUser(ctx, data)
 		}
-		res, err := u.repo.CreateUser(ctx, data.Name, string(pass))
-		if err != nil {
+		// неизвестное имя считается такой же ошибкой, как неверный пароль,
+		// чтобы по ответу нельзя было перебирать существующие имена
+		checkPassword(dummyPassword, data.Password)
+		if err := u.registerFailure(ctx, userKey, ipKey); err != nil {
 			return entity.User{}, err
 		}
-		return res, nil
+		return entity.User{}, myErrors.WrongLoginOrPasswordErr
 	}
 	password, err := u.repo.GetPassword(ctx, user.ID)
 	if err != nil {
 		return entity.User{}, err
 	}
-	if !password.IsEqual(data.Password) {
+	if !checkPassword(password, data.Password) {
+		if err := u.registerFailure(ctx, userKey, ipKey); err != nil {
+			return entity.User{}, err
+		}
 		return entity.User{}, myErrors.WrongLoginOrPasswordErr
 	}
+	if u.policy.Enabled() {
+		// счетчик IP не сбрасывается, иначе одна своя учетная запись
+		// позволила бы бесконечно перебирать чужие пароли
+		if err := u.attempts.Reset(ctx, userKey); err != nil {
+			return entity.User{}, err
+		}
+	}
 	return *user, nil
 }
 
+// checkAttempts не пускает к проверке пароля, пока действует блокировка
+// или не прошла задержка после предыдущей ошибки
+func (u *User) checkAttempts(ctx context.Context, userKey, ipKey string) error {
+	if !u.policy.Enabled() {
+		return nil
+	}
+	attempts, err := u.attempts.Get(ctx, []string{userKey, ipKey})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, a := range attempts {
+		if a.LockedUntil != nil && a.LockedUntil.After(now) {
+			lockErr := myErrors.TooManyAttemptsErr
+			if a.Key == userKey {
+				lockErr = myErrors.AccountLockedErr
+			}
+			return &myErrors.RetryAfterError{Err: lockErr, RetryAfter: a.LockedUntil.Sub(now)}
+		}
+		if a.LastFailureAt.Before(now.Add(-u.policy.Window)) {
+			continue
+		}
+		next := a.LastFailureAt.Add(u.policy.Delay(a.Failures))
+		if next.After(now) {
+			return &myErrors.RetryAfterError{Err: myErrors.TooManyAttemptsErr, RetryAfter: next.Sub(now)}
+		}
+	}
+	return nil
+}
+
+func (u *User) registerFailure(ctx context.Context, userKey, ipKey string) error {
+	if !u.policy.Enabled() {
+		return nil
+	}
+	now := time.Now()
+	limits := map[string]int{userKey: u.policy.MaxFailures, ipKey: u.policy.MaxIPFailures}
+	for _, key := range []string{userKey, ipKey} {
+		attempt, err := u.attempts.RegisterFailure(ctx, key, now.Add(-u.policy.Window))
+		if err != nil {
+			return err
+		}
+		if limits[key] > 0 && attempt.Failures >= limits[key] {
+			err = u.attempts.Lock(ctx, key, now.Add(u.policy.LockDuration))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (u *User) Register(ctx context.Context, data entity.AuthRequest) (entity.User, error) {
+	if !entity.StrongPassword(data.Password) {
+		return entity.User{}, myErrors.WeakPasswordErr
+	}
+	return u.createUser(ctx, data)
+}
+
+func (u *User) createUser(ctx context.Context, data entity.AuthRequest) (entity.User, error) {
+	var pass entity.Password
+	err := pass.Hash(data.Password)
+	if err != nil {
+		return entity.User{}, err
+	}
+	res, err := u.repo.CreateUser(ctx, data.Name, string(pass))
+	if err != nil {
+		return entity.User{}, err
+	}
+	return res, nil
+}
+
 func (u *User) GetUser(ctx context.Context, name string, id uint32) (entity.User, error) {
 	user, err := u.repo.GetUser(ctx, name, id)
 	if err != nil {
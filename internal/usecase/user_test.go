@@ -6,9 +6,11 @@ import (
 	myErrors "avito-winter-2025/internal/utils/errors"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestUserUsecase_GetUser(t *testing.T) {
@@ -56,7 +58,7 @@ func TestUserUsecase_GetUser(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 			userRepo := mock.NewMockUserInterface(ctl)
-			usecase := NewUser(userRepo)
+			usecase := NewUser(userRepo, nil, entity.LockoutPolicy{}, false)
 
 			tt.repoMock(context.Background(), userRepo)
 			got, err := usecase.GetUser(context.Background(), name, id)
@@ -149,10 +151,10 @@ func TestUserUsecase_Auth(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 			userRepo := mock.NewMockUserInterface(ctl)
-			usecase := NewUser(userRepo)
+			usecase := NewUser(userRepo, nil, entity.LockoutPolicy{}, true)
 
 			tt.repoMock(context.Background(), userRepo, data)
-			got, err := usecase.Auth(context.Background(), data)
+			got, err := usecase.Auth(context.Background(), data, "127.0.0.1")
 
 			if (err != nil) != tt.wantError {
 				t.Errorf("UserUsecase.Auth() error = %v, wantErr %v", err, tt.wantError)
@@ -164,3 +166,224 @@ func TestUserUsecase_Auth(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUsecase_AuthLockout(t *testing.T) {
+	data := entity.AuthRequest{Name: "mary", Password: "12345678M"}
+	ip := "10.0.0.1"
+	userKey, ipKey := entity.LoginKeyUser(data.Name), entity.LoginKeyIP(ip)
+	keys := []string{userKey, ipKey}
+	policy := entity.LockoutPolicy{
+		MaxFailures:   3,
+		MaxIPFailures: 10,
+		Window:        15 * time.Minute,
+		LockDuration:  15 * time.Minute,
+		BaseDelay:     time.Second,
+		MaxDelay:      30 * time.Second,
+	}
+	user := &entity.User{ID: 1, Name: "mary", Coins: 1000}
+	wrongPassword := entity.Password("$2a$10$UxLlaLi4rOeWHGSDShFRD.Jtaw6wfjwYfXvlqLXDx7XihxajhdPHa")
+	rightPassword := entity.Password("$2a$10$lrYN1.0L/5NOcDHawDxJpOtn4jouB53uouoz8WnGFCUUDtY97Li/G")
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface)
+		err      error
+		want     entity.User
+	}{
+		{
+			name: "Account is locked",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface) {
+				until := time.Now().Add(time.Minute)
+				attemptRepo.EXPECT().Get(ctx, keys).
+					Return([]entity.LoginAttempt{{Key: userKey, Failures: 3, LastFailureAt: time.Now(), LockedUntil: &until}}, nil)
+			},
+			err:  myErrors.AccountLockedErr,
+			want: entity.User{},
+		},
+		{
+			name: "IP is locked",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface) {
+				until := time.Now().Add(time.Minute)
+				attemptRepo.EXPECT().Get(ctx, keys).
+					Return([]entity.LoginAttempt{{Key: ipKey, Failures: 10, LastFailureAt: time.Now(), LockedUntil: &until}}, nil)
+			},
+			err:  myErrors.TooManyAttemptsErr,
+			want: entity.User{},
+		},
+		{
+			name: "Delay after previous failure",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface) {
+				attemptRepo.EXPECT().Get(ctx, keys).
+					Return([]entity.LoginAttempt{{Key: userKey, Failures: 2, LastFailureAt: time.Now()}}, nil)
+			},
+			err:  myErrors.TooManyAttemptsErr,
+			want: entity.User{},
+		},
+		{
+			name: "Wrong password locks account on last allowed failure",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface) {
+				attemptRepo.EXPECT().Get(ctx, keys).
+					Return([]entity.LoginAttempt{{Key: userKey, Failures: 2, LastFailureAt: time.Now().Add(-time.Minute)}}, nil)
+				userRepo.EXPECT().GetUser(ctx, data.Name, uint32(0)).Return(user, nil)
+				userRepo.EXPECT().GetPassword(ctx, user.ID).Return(wrongPassword, nil)
+				attemptRepo.EXPECT().RegisterFailure(ctx, userKey, gomock.Any()).
+					Return(entity.LoginAttempt{Key: userKey, Failures: 3}, nil)
+				attemptRepo.EXPECT().Lock(ctx, userKey, gomock.Any()).Return(nil)
+				attemptRepo.EXPECT().RegisterFailure(ctx, ipKey, gomock.Any()).
+					Return(entity.LoginAttempt{Key: ipKey, Failures: 3}, nil)
+			},
+			err:  myErrors.WrongLoginOrPasswordErr,
+			want: entity.User{},
+		},
+		{
+			name: "Unknown user without auto registration",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface) {
+				attemptRepo.EXPECT().Get(ctx, keys).Return([]entity.LoginAttempt{}, nil)
+				userRepo.EXPECT().GetUser(ctx, data.Name, uint32(0)).Return(nil, nil)
+				attemptRepo.EXPECT().RegisterFailure(ctx, userKey, gomock.Any()).
+					Return(entity.LoginAttempt{Key: userKey, Failures: 1}, nil)
+				attemptRepo.EXPECT().RegisterFailure(ctx, ipKey, gomock.Any()).
+					Return(entity.LoginAttempt{Key: ipKey, Failures: 1}, nil)
+			},
+			err:  myErrors.WrongLoginOrPasswordErr,
+			want: entity.User{},
+		},
+		{
+			name: "Success resets user counter",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, attemptRepo *mock.MockLoginAttemptInterface) {
+				// ошибки за пределами окна не учитываются
+				attemptRepo.EXPECT().Get(ctx, keys).
+					Return([]entity.LoginAttempt{{Key: userKey, Failures: 2, LastFailureAt: time.Now().Add(-time.Hour)}}, nil)
+				userRepo.EXPECT().GetUser(ctx, data.Name, uint32(0)).Return(user, nil)
+				userRepo.EXPECT().GetPassword(ctx, user.ID).Return(rightPassword, nil)
+				attemptRepo.EXPECT().Reset(ctx, userKey).Return(nil)
+			},
+			err:  nil,
+			want: *user,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			userRepo := mock.NewMockUserInterface(ctl)
+			attemptRepo := mock.NewMockLoginAttemptInterface(ctl)
+			usecase := NewUser(userRepo, attemptRepo, policy, false)
+
+			tt.repoMock(context.Background(), userRepo, attemptRepo)
+			got, err := usecase.Auth(context.Background(), data, ip)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUserUsecase_AuthUnknownUserChecksPassword(t *testing.T) {
+	data := entity.AuthRequest{Name: "mary", Password: "12345678M"}
+	wrongPassword := entity.Password("$2a$10$UxLlaLi4rOeWHGSDShFRD.Jtaw6wfjwYfXvlqLXDx7XihxajhdPHa")
+	// фиктивный хеш должен стоить столько же, сколько настоящий, иначе сравнение быстрее
+	cost, err := bcrypt.Cost([]byte(dummyPassword))
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+
+	tests := []struct {
+		name     string
+		repoMock func(ctx context.Context, userRepo *mock.MockUserInterface)
+		hash     entity.Password
+	}{
+		{
+			name: "Unknown user",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, data.Name, uint32(0)).Return(nil, nil)
+			},
+			hash: dummyPassword,
+		},
+		{
+			name: "Wrong password",
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface) {
+				userRepo.EXPECT().GetUser(ctx, data.Name, uint32(0)).Return(&entity.User{ID: 1, Name: "mary", Coins: 1000}, nil)
+				userRepo.EXPECT().GetPassword(ctx, uint32(1)).Return(wrongPassword, nil)
+			},
+			hash: wrongPassword,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewUser(userRepo, nil, entity.LockoutPolicy{}, false)
+
+			var hashes []entity.Password
+			original := checkPassword
+			checkPassword = func(hash entity.Password, password string) bool {
+				hashes = append(hashes, hash)
+				return original(hash, password)
+			}
+			defer func() { checkPassword = original }()
+
+			tt.repoMock(context.Background(), userRepo)
+			_, err := usecase.Auth(context.Background(), data, "127.0.0.1")
+
+			assert.ErrorIs(t, err, myErrors.WrongLoginOrPasswordErr)
+			assert.Equal(t, []entity.Password{tt.hash}, hashes)
+		})
+	}
+}
+
+func TestUserUsecase_Register(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     entity.AuthRequest
+		repoMock func(ctx context.Context, userRepo *mock.MockUserInterface, data entity.AuthRequest)
+		err      error
+		want     entity.User
+	}{
+		{
+			name:     "Too short password",
+			data:     entity.AuthRequest{Name: "mary", Password: "abc123"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, data entity.AuthRequest) {},
+			err:      myErrors.WeakPasswordErr,
+			want:     entity.User{},
+		},
+		{
+			name:     "Password without digits",
+			data:     entity.AuthRequest{Name: "mary", Password: "abcdefghij"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, data entity.AuthRequest) {},
+			err:      myErrors.WeakPasswordErr,
+			want:     entity.User{},
+		},
+		{
+			name: "Name is taken",
+			data: entity.AuthRequest{Name: "mary", Password: "12345678M"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, data entity.AuthRequest) {
+				userRepo.EXPECT().CreateUser(ctx, data.Name, gomock.Any()).Return(entity.User{}, myErrors.NotUnique)
+			},
+			err:  myErrors.NotUnique,
+			want: entity.User{},
+		},
+		{
+			name: "Success",
+			data: entity.AuthRequest{Name: "mary", Password: "12345678M"},
+			repoMock: func(ctx context.Context, userRepo *mock.MockUserInterface, data entity.AuthRequest) {
+				userRepo.EXPECT().CreateUser(ctx, data.Name, gomock.Any()).Return(entity.User{ID: 1, Name: "mary", Coins: 1000}, nil)
+			},
+			err:  nil,
+			want: entity.User{ID: 1, Name: "mary", Coins: 1000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			userRepo := mock.NewMockUserInterface(ctl)
+			usecase := NewUser(userRepo, nil, entity.LockoutPolicy{}, false)
+
+			tt.repoMock(context.Background(), userRepo, tt.data)
+			got, err := usecase.Register(context.Background(), tt.data)
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
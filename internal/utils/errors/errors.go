@@ -1,6 +1,9 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	NotUnique               = errors.New("Запись с указанными данными уже существует")
@@ -8,4 +11,52 @@ var (
 	NotEnoughCoinErr        = errors.New("У вас недостаточно стредств")
 	NoUserErr               = errors.New("Пользователь не найден")
 	NoMerchErr              = errors.New("Мерч не найден")
+	OutOfStockErr           = errors.New("Мерч закончился")
+	InvalidOrderErr         = errors.New("Некорректный состав заказа")
+	NoInventoryErr          = errors.New("Покупка не найдена")
+	AlreadyReturnedErr      = errors.New("Покупка уже возвращена")
+	ReturnWindowExpiredErr  = errors.New("Срок возврата истек")
+	InvalidScheduleErr      = errors.New("Некорректное расписание перевода")
+	NoScheduledTransferErr  = errors.New("Запланированный перевод не найден")
+	InvalidCoinRequestErr   = errors.New("Нельзя запросить монеты у самого себя")
+	NoCoinRequestErr        = errors.New("Запрос монет не найден или уже закрыт")
+	NoPendingTransferErr    = errors.New("Перевод не найден или уже закрыт")
+	SelfEscrowErr           = errors.New("Нельзя отправить перевод с подтверждением самому себе")
+
+	InvalidRefreshTokenErr = errors.New("Недействительный refresh-токен")
+	WeakPasswordErr        = errors.New("Пароль должен содержать от 8 символов до 72 байт, буквы и цифры")
+	TooManyAttemptsErr     = errors.New("Слишком много попыток входа, попробуйте позже")
+	AccountLockedErr       = errors.New("Учетная запись временно заблокирована")
+	WrongPasswordErr       = errors.New("Неверный текущий пароль")
+	InvalidResetTokenErr   = errors.New("Недействительный или использованный токен сброса пароля")
+
+	IdempotencyKeyReusedErr  = errors.New("Ключ идемпотентности уже использован для другого запроса")
+	IdempotencyInProgressErr = errors.New("Запрос с этим ключом идемпотентности еще обрабатывается")
 )
+
+// RetryAfterError сообщает, через сколько можно повторить запрос
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownUsersError перечисляет имена получателей, которых нет в системе
+type UnknownUsersError struct {
+	Names []string
+}
+
+func (e *UnknownUsersError) Error() string {
+	return NoUserErr.Error()
+}
+
+func (e *UnknownUsersError) Unwrap() error {
+	return NoUserErr
+}
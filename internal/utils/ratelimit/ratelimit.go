@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit описывает token bucket: Requests запросов за Per с запасом Burst.
+// Нулевой Limit означает отсутствие ограничения.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// rate - скорость пополнения корзины в токенах в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// Limiter решает, можно ли выполнить запрос с ключом key. Если нельзя,
+// возвращает время, через которое стоит повторить запрос.
+// Реализация может хранить корзины в памяти процесса или в общем хранилище.
+type Limiter interface {
+	Allow(key string, limit Limit) (bool, time.Duration)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// refill - за сколько пустая корзина наполняется полностью
+	refill time.Duration
+}
+
+// Memory хранит корзины в памяти процесса, поэтому лимиты не делятся между репликами
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	// lastSweep - время последней очистки корзин, которые успели наполниться
+	lastSweep time.Time
+}
+
+const sweepInterval = time.Minute
+
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (m *Memory) Allow(key string, limit Limit) (bool, time.Duration) {
+	if !limit.Enabled() {
+		return true, 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.burst(), updated: now}
+		b.refill = time.Duration(limit.burst() / limit.rate() * float64(time.Second))
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(limit.burst(), b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	b.updated = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / limit.rate()
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// sweep удаляет корзины, которые гарантированно наполнились бы до конца,
+// чтобы карта не росла бесконечно из-за разовых клиентов
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) > b.refill {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMemory(now *time.Time) *Memory {
+	m := NewMemory()
+	m.now = func() time.Time { return *now }
+	return m
+}
+
+func TestMemory_Allow(t *testing.T) {
+	now := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Requests: 60, Per: time.Minute, Burst: 2}
+
+	t.Run("Burst then reject", func(t *testing.T) {
+		m := newTestMemory(&now)
+		ok, _ := m.Allow("user:1", limit)
+		assert.True(t, ok)
+		ok, _ = m.Allow("user:1", limit)
+		assert.True(t, ok)
+		ok, retryAfter := m.Allow("user:1", limit)
+		assert.False(t, ok)
+		assert.Equal(t, time.Second, retryAfter)
+	})
+
+	t.Run("Refill over time", func(t *testing.T) {
+		current := now
+		m := newTestMemory(&current)
+		m.Allow("user:1", limit)
+		m.Allow("user:1", limit)
+		ok, _ := m.Allow("user:1", limit)
+		assert.False(t, ok)
+
+		current = current.Add(time.Second)
+		ok, _ = m.Allow("user:1", limit)
+		assert.True(t, ok)
+	})
+
+	t.Run("Keys are independent", func(t *testing.T) {
+		m := newTestMemory(&now)
+		m.Allow("user:1", limit)
+		m.Allow("user:1", limit)
+		ok, _ := m.Allow("user:2", limit)
+		assert.True(t, ok)
+	})
+
+	t.Run("Burst defaults to requests", func(t *testing.T) {
+		m := newTestMemory(&now)
+		limit := Limit{Requests: 3, Per: time.Minute}
+		for i := 0; i < 3; i++ {
+			ok, _ := m.Allow("ip:127.0.0.1", limit)
+			assert.True(t, ok)
+		}
+		ok, retryAfter := m.Allow("ip:127.0.0.1", limit)
+		assert.False(t, ok)
+		assert.Equal(t, 20*time.Second, retryAfter)
+	})
+
+	t.Run("Disabled limit", func(t *testing.T) {
+		m := newTestMemory(&now)
+		for i := 0; i < 100; i++ {
+			ok, _ := m.Allow("user:1", Limit{})
+			assert.True(t, ok)
+		}
+		assert.Empty(t, m.buckets)
+	})
+
+	t.Run("Full buckets are swept", func(t *testing.T) {
+		current := now
+		m := newTestMemory(&current)
+		m.Allow("user:1", limit)
+		current = current.Add(2 * sweepInterval)
+		m.Allow("user:2", limit)
+		assert.NotContains(t, m.buckets, "user:1")
+		assert.Contains(t, m.buckets, "user:2")
+	})
+}
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDataWithETag(t *testing.T) {
+	data := []string{"pen", "cup"}
+
+	req := httptest.NewRequest(http.MethodGet, "/merch", nil)
+	rw := httptest.NewRecorder()
+	WriteDataWithETag(rw, req, data)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, `["pen","cup"]`, rw.Body.String())
+	etag := rw.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodGet, "/merch", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rw = httptest.NewRecorder()
+	WriteDataWithETag(rw, req, data)
+
+	assert.Equal(t, http.StatusNotModified, rw.Code)
+	assert.Empty(t, rw.Body.String())
+	assert.Equal(t, etag, rw.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/merch", nil)
+	req.Header.Set("If-None-Match", etag)
+	rw = httptest.NewRecorder()
+	WriteDataWithETag(rw, req, []string{"pen"})
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.NotEqual(t, etag, rw.Header().Get("ETag"))
+}
@@ -0,0 +1,148 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("unknown key id")
+)
+
+// Key - ключ подписи access-токенов. Ключ подписывает новые токены начиная с ActiveFrom,
+// пока его не сменит следующий, и принимается при проверке до RetireAt.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	Private    crypto.Signer
+	ActiveFrom time.Time
+	// RetireAt - после этого момента токены с этим kid не принимаются; нулевое значение - без ограничения
+	RetireAt time.Time
+}
+
+func (k Key) retired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// LoadKey читает приватный ключ RSA или Ed25519 в формате PEM и определяет по нему алгоритм
+func LoadKey(id string, path string, activeFrom time.Time, retireAt time.Time) (Key, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+	key := Key{ID: id, ActiveFrom: activeFrom, RetireAt: retireAt}
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(buf); err == nil {
+		key.Method, key.Private = jwt.SigningMethodRS256, rsaKey
+		return key, nil
+	}
+	edKey, err := jwt.ParseEdPrivateKeyFromPEM(buf)
+	if err != nil {
+		return Key{}, fmt.Errorf("key %s: unsupported private key", id)
+	}
+	key.Method, key.Private = jwt.SigningMethodEdDSA, edKey.(crypto.Signer)
+	return key, nil
+}
+
+// KeySet - набор ключей с расписанием ротации
+type KeySet struct {
+	keys []Key
+	now  func() time.Time
+}
+
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if k.ID == "" || seen[k.ID] {
+			return nil, fmt.Errorf("key id %q is empty or duplicated", k.ID)
+		}
+		seen[k.ID] = true
+		if k.Method != jwt.SigningMethodRS256 && k.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("key %s: unsupported algorithm", k.ID)
+		}
+		if !k.RetireAt.IsZero() && !k.ActiveFrom.Before(k.RetireAt) {
+			return nil, fmt.Errorf("key %s: retire_at must be after active_from", k.ID)
+		}
+	}
+	sorted := append([]Key{}, keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+	return &KeySet{keys: sorted, now: time.Now}, nil
+}
+
+// Signing возвращает самый новый из уже активных и не выведенных ключей
+func (s *KeySet) Signing() (Key, error) {
+	now := s.now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		k := s.keys[i]
+		if !k.ActiveFrom.After(now) && !k.retired(now) {
+			return k, nil
+		}
+	}
+	return Key{}, ErrNoSigningKey
+}
+
+// Verify возвращает публичный ключ для проверки токена с указанным kid.
+// Ключи, которые еще не начали подписывать, тоже принимаются: их заранее публикуют в JWKS.
+func (s *KeySet) Verify(kid string) (Key, crypto.PublicKey, error) {
+	now := s.now()
+	for _, k := range s.keys {
+		if k.ID == kid && !k.retired(now) {
+			return k, k.Private.Public(), nil
+		}
+	}
+	return Key{}, nil, ErrUnknownKey
+}
+
+// JWK - публичный ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS возвращает публичные части всех не выведенных ключей, включая запланированные
+func (s *KeySet) JWKS() JWKS {
+	now := s.now()
+	res := JWKS{Keys: []JWK{}}
+	for _, k := range s.keys {
+		if k.retired(now) {
+			continue
+		}
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+		switch pub := k.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		res.Keys = append(res.Keys, jwk)
+	}
+	return res
+}
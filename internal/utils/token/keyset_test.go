@@ -0,0 +1,153 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRSAKey(t *testing.T, id string, activeFrom time.Time, retireAt time.Time) Key {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Key{ID: id, Method: jwt.SigningMethodRS256, Private: private, ActiveFrom: activeFrom, RetireAt: retireAt}
+}
+
+func newEdKey(t *testing.T, id string, activeFrom time.Time, retireAt time.Time) Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Key{ID: id, Method: jwt.SigningMethodEdDSA, Private: private, ActiveFrom: activeFrom, RetireAt: retireAt}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := newRSAKey(t, "old", now.Add(-48*time.Hour), now.Add(time.Hour))
+	current := newEdKey(t, "current", now.Add(-time.Hour), time.Time{})
+	next := newRSAKey(t, "next", now.Add(24*time.Hour), time.Time{})
+	keys, err := NewKeySet(next, old, current)
+	assert.NoError(t, err)
+	keys.now = func() time.Time { return now }
+
+	signing, err := keys.Signing()
+	assert.NoError(t, err)
+	assert.Equal(t, "current", signing.ID)
+
+	// следующий ключ публикуется заранее
+	jwks := keys.JWKS()
+	assert.Len(t, jwks.Keys, 3)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Alg)
+	assert.Equal(t, "RSA", jwks.Keys[2].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[2].E)
+
+	keys.now = func() time.Time { return now.Add(25 * time.Hour) }
+	signing, err = keys.Signing()
+	assert.NoError(t, err)
+	assert.Equal(t, "next", signing.ID)
+	_, _, err = keys.Verify("old")
+	assert.Equal(t, ErrUnknownKey, err)
+	assert.Len(t, keys.JWKS().Keys, 2)
+}
+
+func TestNewKeySet_Invalid(t *testing.T) {
+	now := time.Now()
+	_, err := NewKeySet(newEdKey(t, "a", now, time.Time{}), newEdKey(t, "a", now, time.Time{}))
+	assert.Error(t, err)
+	_, err = NewKeySet(newEdKey(t, "a", now, now.Add(-time.Hour)))
+	assert.Error(t, err)
+
+	empty, err := NewKeySet()
+	assert.NoError(t, err)
+	_, err = empty.Signing()
+	assert.Equal(t, ErrNoSigningKey, err)
+}
+
+func TestJWT_KeySet(t *testing.T) {
+	now := time.Now()
+	key := newEdKey(t, "ed", now.Add(-time.Hour), time.Time{})
+	keys, err := NewKeySet(key)
+	assert.NoError(t, err)
+	j := JWT{Secret: []byte("secret"), ExpTime: time.Hour, Keys: keys}
+
+	signed, err := j.GenerateToken(1, "sofia", "employee")
+	assert.NoError(t, err)
+	claims, err := j.ParseToken(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), claims.UserID)
+
+	// старые HS256-токены без kid принимаются, пока задан секрет
+	legacy, err := JWT{Secret: j.Secret, ExpTime: time.Hour}.GenerateToken(1, "sofia", "employee")
+	assert.NoError(t, err)
+	_, err = j.ParseToken(legacy)
+	assert.NoError(t, err)
+	_, err = JWT{ExpTime: time.Hour, Keys: keys}.ParseToken(legacy)
+	assert.Error(t, err)
+
+	// HS256-токен с kid асимметричного ключа не должен проходить проверку
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:           1,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour))},
+	})
+	forged.Header["kid"] = "ed"
+	forgedString, err := forged.SignedString([]byte(key.Private.Public().(ed25519.PublicKey)))
+	assert.NoError(t, err)
+	_, err = j.ParseToken(forgedString)
+	assert.Error(t, err)
+
+	unknown := newEdKey(t, "unknown", now.Add(-time.Hour), time.Time{})
+	otherKeys, err := NewKeySet(unknown)
+	assert.NoError(t, err)
+	foreign, err := JWT{ExpTime: time.Hour, Keys: otherKeys}.GenerateToken(1, "sofia", "employee")
+	assert.NoError(t, err)
+	_, err = j.ParseToken(foreign)
+	assert.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDer, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPath := filepath.Join(dir, "ed.pem")
+	os.WriteFile(edPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edDer}), 0600)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPath := filepath.Join(dir, "rsa.pem")
+	os.WriteFile(rsaPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPrivate)}), 0600)
+
+	garbagePath := filepath.Join(dir, "garbage.pem")
+	os.WriteFile(garbagePath, []byte("garbage"), 0600)
+
+	key, err := LoadKey("ed", edPath, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, jwt.SigningMethodEdDSA, key.Method)
+
+	key, err = LoadKey("rsa", rsaPath, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, jwt.SigningMethodRS256, key.Method)
+
+	_, err = LoadKey("garbage", garbagePath, time.Time{}, time.Time{})
+	assert.Error(t, err)
+	_, err = LoadKey("missing", filepath.Join(dir, "missing.pem"), time.Time{}, time.Time{})
+	assert.Error(t, err)
+}
@@ -1,6 +1,11 @@
 package token
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,12 +14,17 @@ import (
 type Claims struct {
 	UserID uint32 `json:"user_id"`
 	Name   string `json:"name"`
+	// Role отсутствует в токенах, выпущенных до появления ролей
+	Role string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// JWT выпускает и проверяет access-токены. Если задан Keys, токены подписываются
+// асимметричным ключом из набора, а Secret нужен только для проверки старых HS256-токенов без kid.
 type JWT struct {
 	Secret  []byte
 	ExpTime time.Duration
+	Keys    *KeySet
 }
 
 func NewJWT(secret string, duration string) (JWT, error) {
@@ -31,16 +41,94 @@ func NewJWT(secret string, duration string) (JWT, error) {
 	}, nil
 }
 
-func (j JWT) GenerateToken(userID uint32, name string) (string, error) {
+var ErrInvalidToken = errors.New("invalid token")
+
+// GenerateToken выпускает access-токен. Уникальный jti позволяет отозвать токен до истечения срока.
+func (j JWT) GenerateToken(userID uint32, name string, role string) (string, error) {
+	id, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
 	claims := Claims{
 		UserID: userID,
 		Name:   name,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.ExpTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.Secret)
+	if j.Keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(j.Secret)
+	}
+	key, err := j.Keys.Signing()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// ParseToken проверяет подпись и срок действия access-токена
+func (j JWT) ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	t, err := jwt.ParseWithClaims(tokenString, claims, j.keyFunc, jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if !t.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// keyFunc выбирает ключ проверки по kid. Алгоритм токена должен совпадать с алгоритмом ключа,
+// иначе подпись публичным ключом можно было бы подделать через HS256.
+func (j JWT) keyFunc(tok *jwt.Token) (interface{}, error) {
+	kid, _ := tok.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := tok.Method.(*jwt.SigningMethodHMAC); !ok || len(j.Secret) == 0 {
+			return nil, ErrInvalidToken
+		}
+		return j.Secret, nil
+	}
+	if j.Keys == nil {
+		return nil, ErrInvalidToken
+	}
+	key, pub, err := j.Keys.Verify(kid)
+	if err != nil {
+		return nil, err
+	}
+	if tok.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return pub, nil
+}
+
+// NewTokenID возвращает случайный идентификатор для jti и семейства refresh-токенов
+func NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// NewRefreshToken возвращает случайный refresh-токен для клиента и его хэш для хранения в базе
+func NewRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashRefreshToken(token), nil
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
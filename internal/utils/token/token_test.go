@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -47,3 +48,65 @@ func TestNewJWT(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateToken(t *testing.T) {
+	j := JWT{Secret: []byte("secret"), ExpTime: time.Hour}
+	tokenString, err := j.GenerateToken(1, "sofia", "admin")
+	assert.NoError(t, err)
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(tok *jwt.Token) (interface{}, error) {
+		return j.Secret, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), claims.UserID)
+	assert.Equal(t, "sofia", claims.Name)
+	assert.Equal(t, "admin", claims.Role)
+	assert.Len(t, claims.ID, 32)
+}
+
+func TestParseToken(t *testing.T) {
+	j := JWT{Secret: []byte("secret"), ExpTime: time.Hour}
+	valid, err := j.GenerateToken(1, "sofia", "employee")
+	assert.NoError(t, err)
+	expired, err := JWT{Secret: j.Secret, ExpTime: -time.Minute}.GenerateToken(1, "sofia", "employee")
+	assert.NoError(t, err)
+	foreign, err := JWT{Secret: []byte("another"), ExpTime: time.Hour}.GenerateToken(1, "sofia", "employee")
+	assert.NoError(t, err)
+	withoutExp, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1}).SignedString(j.Secret)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "Valid", token: valid, wantErr: false},
+		{name: "Expired", token: expired, wantErr: true},
+		{name: "Wrong secret", token: foreign, wantErr: true},
+		{name: "Without expiration", token: withoutExp, wantErr: true},
+		{name: "Garbage", token: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := j.ParseToken(tt.token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, uint32(1), claims.UserID)
+		})
+	}
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, hash)
+	assert.Equal(t, hash, HashRefreshToken(token))
+
+	another, _, err := NewRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, another)
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job выполняет одну порцию фоновой работы и возвращает число обработанных записей
+type Job func(ctx context.Context) (int, error)
+
+// Run запускает job каждые every, пока не отменен ctx. Ошибки запуска только логируются:
+// следующий запуск повторит необработанную работу. Нулевой every отключает задачу.
+// Отмена ctx не прерывает уже начатую порцию: job получает контекст без отмены,
+// а Run возвращается только после ее завершения.
+func Run(ctx context.Context, name string, every time.Duration, logger *zap.Logger, job Job) {
+	if every <= 0 {
+		logger.Warn("background job disabled", zap.String("job", name))
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		processed, err := job(context.WithoutCancel(ctx))
+		if err != nil {
+			logger.Error("background job failed", zap.String("job", name), zap.Error(err))
+		} else if processed > 0 {
+			logger.Info("background job done", zap.String("job", name), zap.Int("processed", processed))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
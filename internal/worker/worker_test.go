@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, "test", time.Millisecond, zap.NewNop(), func(ctx context.Context) (int, error) {
+			calls++
+			if calls == 3 {
+				cancel()
+			}
+			return 1, errors.New("job error")
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestRun_Disabled(t *testing.T) {
+	Run(context.Background(), "test", 0, zap.NewNop(), func(ctx context.Context) (int, error) {
+		t.Fatal("disabled job must not run")
+		return 0, nil
+	})
+}
+
+func TestRun_FinishesJobAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var jobErr error
+	Run(ctx, "test", time.Millisecond, zap.NewNop(), func(ctx context.Context) (int, error) {
+		cancel()
+		// начатая порция дорабатывает с неотмененным контекстом
+		jobErr = ctx.Err()
+		return 1, nil
+	})
+	assert.NoError(t, jobErr)
+}
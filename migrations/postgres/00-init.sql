@@ -3,14 +3,18 @@ CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE TABLE IF NOT EXISTS merch (
     id            INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     name          TEXT UNIQUE NOT NULL,
-    cost          INTEGER
+    cost          INTEGER,
+    -- NULL означает, что количество товара не ограничено
+    stock         INTEGER CONSTRAINT stock_value CHECK (stock >= 0),
+    archived_at   TIMESTAMPTZ
 );
 
 CREATE TABLE IF NOT EXISTS "user" (
     id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     name TEXT UNIQUE NOT NULL,
     password TEXT NOT NULL,
-    coins INTEGER CONSTRAINT coins_value CHECK (coins >= 0) NOT NULL
+    coins INTEGER CONSTRAINT coins_value CHECK (coins >= 0) NOT NULL,
+    role TEXT NOT NULL DEFAULT 'employee'
 );
 
 CREATE TABLE IF NOT EXISTS coin_history (
@@ -18,12 +22,233 @@ CREATE TABLE IF NOT EXISTS coin_history (
     from_user INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
     to_user INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
     amount INTEGER CONSTRAINT amount_value CHECK (amount > 0) NOT NULL,
+    -- необязательные сообщение и категория перевода, очищаются на уровне приложения
+    message TEXT NOT NULL DEFAULT '' CONSTRAINT message_length CHECK (char_length(message) <= 200),
+    category TEXT NOT NULL DEFAULT '',
     created_at TIMESTAMPTZ DEFAULT NOW()
 );
 
+CREATE INDEX IF NOT EXISTS coin_history_from_user_idx ON coin_history (from_user, id);
+CREATE INDEX IF NOT EXISTS coin_history_to_user_idx ON coin_history (to_user, id);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    total INTEGER CONSTRAINT total_value CHECK (total > 0) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS order_lines (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    order_id INTEGER REFERENCES orders (id) ON DELETE CASCADE,
+    merch_id INTEGER REFERENCES merch (id) ON DELETE SET NULL,
+    quantity INTEGER CONSTRAINT quantity_value CHECK (quantity > 0) NOT NULL,
+    cost INTEGER NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS inventory (
     id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     merch_id INTEGER REFERENCES merch (id) ON DELETE SET NULL,
     user_id INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    order_id INTEGER REFERENCES orders (id) ON DELETE SET NULL,
+    -- цена, по которой товар был куплен; возвращается при возврате
+    cost INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    returned_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS refund (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    inventory_id INTEGER UNIQUE REFERENCES inventory (id) ON DELETE SET NULL,
+    user_id INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    amount INTEGER CONSTRAINT refund_amount_value CHECK (amount >= 0) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    key TEXT NOT NULL,
+    user_id INTEGER REFERENCES "user" (id) ON DELETE CASCADE,
+    request_hash TEXT NOT NULL,
+    status_code INTEGER,
+    response BYTEA,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, user_id)
+);
+
+-- Неудачные попытки входа; key имеет вид 'user:<name>' или 'ip:<address>'
+CREATE TABLE IF NOT EXISTS login_attempt (
+    key TEXT PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    -- sha256 от токена; сам токен знает только клиент
+    token_hash TEXT UNIQUE NOT NULL,
+    family TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS refresh_token_family_idx ON refresh_token (family);
+
+-- Отозванные access-токены; записи нужны только до истечения срока токена
+CREATE TABLE IF NOT EXISTS revoked_token (
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS revoked_token_revoked_at_idx ON revoked_token (revoked_at);
+
+-- Время последней смены пароля; access-токены, выпущенные раньше, не принимаются
+CREATE TABLE IF NOT EXISTS token_cutoff (
+    user_id INTEGER PRIMARY KEY REFERENCES "user" (id) ON DELETE CASCADE,
+    not_before TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS token_cutoff_not_before_idx ON token_cutoff (not_before);
+
+-- Одноразовые токены сброса пароля, выданные администратором
+CREATE TABLE IF NOT EXISTS password_reset (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    created_by INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+-- Журнал движения монет по принципу двойной записи. "user".coins остается
+-- кэшем баланса, который блокируется при списании; источником истины является журнал.
+CREATE TABLE IF NOT EXISTS ledger_transaction (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    kind TEXT NOT NULL CONSTRAINT kind_value
+        CHECK (kind IN ('initial_grant', 'transfer', 'purchase', 'refund', 'adjustment',
+            'escrow_hold', 'escrow_release', 'escrow_return', 'allowance', 'allowance_expiry')),
+    -- id записи в coin_history, inventory, orders, refund, pending_transfer или allowance_grant в зависимости от kind
+    reference_id INTEGER,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entry (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    transaction_id INTEGER NOT NULL REFERENCES ledger_transaction (id) ON DELETE CASCADE,
+    -- 'user' для счетов пользователей, иначе имя системного счета
+    account TEXT NOT NULL CONSTRAINT account_value CHECK (account IN ('user', 'issuance', 'shop', 'escrow')),
+    user_id INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    amount BIGINT CONSTRAINT entry_amount_value CHECK (amount <> 0) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entry_transaction_idx ON ledger_entry (transaction_id);
+CREATE INDEX IF NOT EXISTS ledger_entry_user_idx ON ledger_entry (user_id);
+
+-- Сумма ног каждой проводки должна быть равна нулю; проверяется при коммите,
+-- чтобы ноги можно было вставлять по одной
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entry WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entry_balanced ON ledger_entry;
+CREATE CONSTRAINT TRIGGER ledger_entry_balanced
+    AFTER INSERT ON ledger_entry
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- Запланированные переводы. interval_seconds = 0 означает разовый перевод,
+-- иначе перевод повторяется с этим периодом начиная с next_run_at
+CREATE TABLE IF NOT EXISTS scheduled_transfer (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    from_user INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    to_user INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    amount INTEGER CONSTRAINT scheduled_amount_value CHECK (amount > 0) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    interval_seconds BIGINT NOT NULL DEFAULT 0 CONSTRAINT interval_value CHECK (interval_seconds >= 0),
+    next_run_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active' CONSTRAINT scheduled_status_value
+        CHECK (status IN ('active', 'paused', 'completed', 'failed', 'cancelled')),
+    last_run_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    -- число неудачных запусков подряд
+    failures INTEGER NOT NULL DEFAULT 0,
     created_at TIMESTAMPTZ DEFAULT NOW()
-);
\ No newline at end of file
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfer_from_user_idx ON scheduled_transfer (from_user, id);
+CREATE INDEX IF NOT EXISTS scheduled_transfer_due_idx ON scheduled_transfer (next_run_at) WHERE status = 'active';
+
+-- Запросы монет: requester просит payer перевести ему amount монет.
+-- Просроченным считается запрос в статусе pending после expires_at
+CREATE TABLE IF NOT EXISTS coin_request (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    requester INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    payer INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    amount INTEGER CONSTRAINT coin_request_amount_value CHECK (amount > 0) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CONSTRAINT coin_request_status_value
+        CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    CONSTRAINT coin_request_not_self CHECK (requester <> payer)
+);
+
+CREATE INDEX IF NOT EXISTS coin_request_requester_idx ON coin_request (requester, id);
+CREATE INDEX IF NOT EXISTS coin_request_payer_idx ON coin_request (payer, id);
+
+-- Переводы с подтверждением: монеты списываются с отправителя на счет escrow
+-- и зачисляются получателю только после принятия. Непринятые до expires_at
+-- переводы возвращаются отправителю фоновым обработчиком
+CREATE TABLE IF NOT EXISTS pending_transfer (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    -- после удаления участника перевод остается, чтобы удержанные монеты не пропали со счета escrow
+    from_user INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    to_user INTEGER REFERENCES "user" (id) ON DELETE SET NULL,
+    amount INTEGER CONSTRAINT pending_amount_value CHECK (amount > 0) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'held' CONSTRAINT pending_status_value
+        CHECK (status IN ('held', 'accepted', 'rejected', 'returned')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS pending_transfer_from_user_idx ON pending_transfer (from_user, id);
+CREATE INDEX IF NOT EXISTS pending_transfer_to_user_idx ON pending_transfer (to_user, id);
+CREATE INDEX IF NOT EXISTS pending_transfer_expires_idx ON pending_transfer (expires_at) WHERE status = 'held';
+CREATE INDEX IF NOT EXISTS pending_transfer_orphaned_idx ON pending_transfer (id) WHERE status = 'held' AND to_user IS NULL;
+
+-- Начисления по политикам периодического пополнения; заодно служат журналом аудита.
+-- Для каждой политики пользователь получает не больше одного начисления за период
+CREATE TABLE IF NOT EXISTS allowance_grant (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    policy TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    -- первый день периода, за который сделано начисление
+    period DATE NOT NULL,
+    amount INTEGER CONSTRAINT allowance_amount_value CHECK (amount > 0) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    -- NULL означает, что начисленные монеты не сгорают
+    expires_at TIMESTAMPTZ,
+    expired_at TIMESTAMPTZ,
+    -- сколько неизрасходованных монет списано при сгорании
+    expired_amount INTEGER NOT NULL DEFAULT 0 CONSTRAINT expired_amount_value CHECK (expired_amount >= 0 AND expired_amount <= amount),
+    UNIQUE (policy, user_id, period)
+);
+
+CREATE INDEX IF NOT EXISTS allowance_grant_user_idx ON allowance_grant (user_id, id);
+CREATE INDEX IF NOT EXISTS allowance_grant_expires_idx ON allowance_grant (expires_at) WHERE expired_at IS NULL;
@@ -1,3 +1,5 @@
 INSERT INTO merch(name, cost) VALUES ('t-shirt', 80), ('cup', 20) , ('book', 50),
     ('pen', 10), ('powerbank', 200), ('hoody', 300), ('umbrella', 200), 
-    ('socks', 10), ('wallet', 50), ('pink-hoody', 500);
\ No newline at end of file
+    ('socks', 10), ('wallet', 50), ('pink-hoody', 500);
+
+UPDATE merch SET stock = 50 WHERE name = 'pink-hoody';
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- Обновление базы, созданной до появления новых столбцов. CREATE TABLE IF NOT EXISTS
+-- в 00-init.sql не меняет уже существующие таблицы, поэтому столбцы добавляются здесь.
+-- Перед этим файлом выполняется 00-init.sql: он создает недостающие таблицы (orders
+-- нужна для inventory.order_id) и индексы. Оба файла можно запускать повторно;
+-- на новой базе все команды ничего не меняют.
+
+ALTER TABLE merch
+    -- NULL означает, что количество товара не ограничено
+    ADD COLUMN IF NOT EXISTS stock INTEGER CONSTRAINT stock_value CHECK (stock >= 0),
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+ALTER TABLE "user"
+    ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'employee';
+
+ALTER TABLE coin_history
+    ADD COLUMN IF NOT EXISTS message TEXT NOT NULL DEFAULT '' CONSTRAINT message_length CHECK (char_length(message) <= 200),
+    ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE inventory
+    ADD COLUMN IF NOT EXISTS order_id INTEGER REFERENCES orders (id) ON DELETE SET NULL,
+    -- у покупок, сделанных до обновления, цена 0: при возврате берется текущая цена товара
+    ADD COLUMN IF NOT EXISTS cost INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS returned_at TIMESTAMPTZ;
@@ -0,0 +1,26 @@
+-- Перенос балансов, накопленных до появления журнала. Для каждого пользователя,
+-- чей кэш "user".coins расходится с суммой его проводок, проводится разница со
+-- счета issuance: initial_grant, если проводок еще нет, иначе adjustment.
+-- Повторный запуск ничего не меняет. Выполнять при остановленном сервисе,
+-- чтобы между чтением суммы и проводкой баланс не изменился.
+DO $$
+DECLARE
+    r RECORD;
+    t_id INTEGER;
+BEGIN
+    FOR r IN
+        SELECT u.id, u.coins - coalesce(sum(e.amount), 0) AS diff, count(e.id) = 0 AS fresh
+        FROM "user" AS u
+        LEFT JOIN ledger_entry AS e ON e.account = 'user' AND e.user_id = u.id
+        GROUP BY u.id
+        HAVING u.coins - coalesce(sum(e.amount), 0) <> 0
+        ORDER BY u.id
+    LOOP
+        INSERT INTO ledger_transaction (kind, created_at)
+        VALUES (CASE WHEN r.fresh THEN 'initial_grant' ELSE 'adjustment' END, NOW())
+        RETURNING id INTO t_id;
+        INSERT INTO ledger_entry (transaction_id, account, user_id, amount)
+        VALUES (t_id, 'user', r.id, r.diff), (t_id, 'issuance', NULL, -r.diff);
+    END LOOP;
+END;
+$$;
@@ -0,0 +1,191 @@
+package api_test
+
+import (
+	"avito-winter-2025/internal/delivery"
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"avito-winter-2025/internal/usecase"
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+const (
+	concurrentUsers     = 10
+	concurrentTransfers = 500
+	concurrentPurchases = 100
+)
+
+type ConcurrencyTestSuite struct {
+	db           *pgxpool.Pool
+	coinHandler  *delivery.CoinHandler
+	shopHandler  *delivery.ShopHandler
+	users        []entity.User
+	initialCoins uint32
+	suite.Suite
+}
+
+func (s *ConcurrencyTestSuite) SetupTest() {
+	if err := godotenv.Load(); err != nil {
+		fmt.Println(err)
+	}
+	db := InitPostgres(os.Getenv("TEST_DB_USER"), os.Getenv("TEST_DB_PASSWORD"), os.Getenv("TEST_DB_HOST"), os.Getenv("TEST_DB_PORT"), os.Getenv("TEST_DB_NAME"))
+	if db == nil {
+		s.T().Fatal("Failed to initialize database connection")
+	}
+	s.db = db
+	userRepo := repo.NewUser(db)
+	coinRepo := repo.NewCoin(db, zap.NewNop())
+	merchRepo := repo.NewMerch(db)
+	userUC := usecase.NewUser(userRepo, repo.NewLoginAttempt(db), entity.LockoutPolicy{}, false)
+	coinUC := usecase.NewCoin(coinRepo, userRepo)
+	merchUC := usecase.NewMerch(merchRepo, coinRepo)
+	s.coinHandler = delivery.NewCoinHandler(coinUC, userUC, usecase.NewEscrow(repo.NewEscrow(db, zap.NewNop()), time.Hour, 10))
+	s.shopHandler = delivery.NewShopHandler(merchUC, userUC, coinUC)
+	s.initialCoins = 100
+
+	query := `INSERT INTO "user" (name, password, coins) VALUES ($1, $2, $3)
+				RETURNING id, name, coins`
+	ctx := context.Background()
+	s.db.Exec(ctx, `DELETE FROM "user"`)
+	s.users = make([]entity.User, concurrentUsers)
+	for i := range s.users {
+		s.db.QueryRow(ctx, query, "user"+strconv.Itoa(i), "12345", s.initialCoins).
+			Scan(&s.users[i].ID, &s.users[i].Name, &s.users[i].Coins)
+	}
+}
+
+func (s *ConcurrencyTestSuite) TearDownTest() {
+	s.db.Close()
+}
+
+func (s *ConcurrencyTestSuite) sendCoin(from entity.User, to entity.User, amount int) int {
+	data := []byte(`{"toUser":"` + to.Name + `", "amount":` + strconv.Itoa(amount) + `}`)
+	req := httptest.NewRequest("POST", "/sendCoin", bytes.NewBuffer(data))
+	req = req.WithContext(context.WithValue(req.Context(), userKey, from))
+
+	rw := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/sendCoin", s.coinHandler.SendCoin)
+	router.ServeHTTP(rw, req)
+	return rw.Code
+}
+
+func (s *ConcurrencyTestSuite) TestSendCoin_TotalSupplyIsConserved() {
+	var wg sync.WaitGroup
+	codes := make(chan int, concurrentTransfers)
+	for i := 0; i < concurrentTransfers; i++ {
+		from := s.users[rand.Intn(len(s.users))]
+		to := s.users[rand.Intn(len(s.users))]
+		amount := rand.Intn(int(s.initialCoins)) + 1
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- s.sendCoin(from, to, amount)
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		// недостаток средств должен давать 400, а не ошибку ограничения БД
+		s.Contains([]int{http.StatusOK, http.StatusBadRequest}, code)
+	}
+
+	var total, negative uint32
+	ctx := context.Background()
+	s.db.QueryRow(ctx, `SELECT COALESCE(SUM(coins), 0) FROM "user"`).Scan(&total)
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM "user" WHERE coins < 0`).Scan(&negative)
+	s.Equal(s.initialCoins*concurrentUsers, total)
+	s.Equal(uint32(0), negative)
+}
+
+func (s *ConcurrencyTestSuite) TestSendCoin_MutualTransfers() {
+	a, b := s.users[0], s.users[1]
+	var wg sync.WaitGroup
+	codes := make(chan int, concurrentTransfers)
+	for i := 0; i < concurrentTransfers; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- s.sendCoin(from, to, 1)
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		// встречные переводы не должны заканчиваться взаимоблокировкой
+		s.Contains([]int{http.StatusOK, http.StatusBadRequest}, code)
+	}
+
+	var balanceA, balanceB uint32
+	ctx := context.Background()
+	s.db.QueryRow(ctx, `SELECT coins FROM "user" WHERE id = $1`, a.ID).Scan(&balanceA)
+	s.db.QueryRow(ctx, `SELECT coins FROM "user" WHERE id = $1`, b.ID).Scan(&balanceB)
+	s.Equal(2*s.initialCoins, balanceA+balanceB)
+}
+
+func (s *ConcurrencyTestSuite) TestBuyMerch_NoOverspending() {
+	merch := "pen"
+	var cost uint32
+	ctx := context.Background()
+	s.db.QueryRow(ctx, `SELECT cost FROM merch WHERE name = $1`, merch).Scan(&cost)
+	buyer := s.users[0]
+
+	var wg sync.WaitGroup
+	codes := make(chan int, concurrentPurchases)
+	for i := 0; i < concurrentPurchases; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/buy/"+merch, nil)
+			req = req.WithContext(context.WithValue(req.Context(), userKey, buyer))
+
+			rw := httptest.NewRecorder()
+			router := mux.NewRouter()
+			router.HandleFunc("/buy/{item}", s.shopHandler.BuyMerch)
+			router.ServeHTTP(rw, req)
+			codes <- rw.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	var success uint32
+	for code := range codes {
+		s.Contains([]int{http.StatusOK, http.StatusBadRequest}, code)
+		if code == http.StatusOK {
+			success++
+		}
+	}
+
+	var balance, bought uint32
+	s.db.QueryRow(ctx, `SELECT coins FROM "user" WHERE id = $1`, buyer.ID).Scan(&balance)
+	s.db.QueryRow(ctx, `SELECT COUNT(*) FROM inventory WHERE user_id = $1`, buyer.ID).Scan(&bought)
+	s.Equal(s.initialCoins/cost, success)
+	s.Equal(success, bought)
+	s.Equal(s.initialCoins-success*cost, balance)
+}
+
+func TestConcurrencyTestSuite(t *testing.T) {
+	suite.Run(t, new(ConcurrencyTestSuite))
+}
@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"avito-winter-2025/internal/delivery"
+	"avito-winter-2025/internal/entity"
+	"avito-winter-2025/internal/repo"
+	"avito-winter-2025/internal/usecase"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type RefundTestSuite struct {
+	router *mux.Router
+	user   entity.User
+	suite.Suite
+}
+
+func (s *RefundTestSuite) SetupTest() {
+	if err := godotenv.Load(); err != nil {
+		fmt.Println(err)
+	}
+	db := InitPostgres(os.Getenv("TEST_DB_USER"), os.Getenv("TEST_DB_PASSWORD"), os.Getenv("TEST_DB_HOST"), os.Getenv("TEST_DB_PORT"), os.Getenv("TEST_DB_NAME"))
+	if db == nil {
+		s.T().Fatal("Failed to initialize database connection")
+	}
+	s.T().Cleanup(db.Close)
+	merchRepo := repo.NewMerch(db)
+	userRepo := repo.NewUser(db)
+	coinRepo := repo.NewCoin(db, zap.NewNop())
+	userUC := usecase.NewUser(userRepo, repo.NewLoginAttempt(db), entity.LockoutPolicy{}, false)
+	shopHandler := delivery.NewShopHandler(usecase.NewMerch(merchRepo, coinRepo), userUC, usecase.NewCoin(coinRepo, userRepo))
+	refundHandler := delivery.NewRefundHandler(usecase.NewRefund(repo.NewRefund(db), time.Hour))
+
+	s.router = mux.NewRouter()
+	s.router.HandleFunc("/buy/{item}", shopHandler.BuyMerch)
+	s.router.HandleFunc("/inventory", refundHandler.List)
+	s.router.HandleFunc("/inventory/{id}/return", refundHandler.Return)
+
+	ctx := context.Background()
+	db.Exec(ctx, `DELETE FROM "user"`)
+	s.user = entity.User{Name: "sofia", Coins: 1000}
+	db.QueryRow(ctx, `INSERT INTO "user" (name, password, coins) VALUES ($1, $2, $3) RETURNING id`,
+		s.user.Name, "12345", s.user.Coins).Scan(&s.user.ID)
+}
+
+func (s *RefundTestSuite) do(method string, url string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, nil)
+	req = req.WithContext(context.WithValue(req.Context(), userKey, s.user))
+	rw := httptest.NewRecorder()
+	s.router.ServeHTTP(rw, req)
+	return rw
+}
+
+func (s *RefundTestSuite) inventory() []entity.InventoryItem {
+	rw := s.do("GET", "/inventory")
+	s.Require().Equal(http.StatusOK, rw.Code)
+	var items []entity.InventoryItem
+	s.Require().NoError(json.Unmarshal(rw.Body.Bytes(), &items))
+	return items
+}
+
+func (s *RefundTestSuite) TestReturn_ByIdFromInventory() {
+	s.Require().Equal(http.StatusOK, s.do("GET", "/buy/t-shirt").Code)
+
+	// id покупки клиент узнает из списка инвентаря
+	items := s.inventory()
+	s.Require().Len(items, 1)
+	s.Equal("t-shirt", items[0].Type)
+	s.Nil(items[0].ReturnedAt)
+
+	rw := s.do("POST", fmt.Sprintf("/inventory/%d/return", items[0].ID))
+	s.Require().Equal(http.StatusOK, rw.Code)
+	var refund entity.Refund
+	s.Require().NoError(json.Unmarshal(rw.Body.Bytes(), &refund))
+	s.Equal(items[0].ID, refund.InventoryID)
+	s.Equal(items[0].Cost, refund.Amount)
+
+	items = s.inventory()
+	s.Require().Len(items, 1)
+	s.NotNil(items[0].ReturnedAt)
+
+	// повторный возврат той же покупки отклоняется
+	s.Equal(http.StatusConflict, s.do("POST", fmt.Sprintf("/inventory/%d/return", items[0].ID)).Code)
+}
+
+func TestRefundTestSuite(t *testing.T) {
+	suite.Run(t, new(RefundTestSuite))
+}
@@ -14,11 +14,13 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
 )
 
 type SendCoinTestSuite struct {
@@ -39,10 +41,10 @@ func (s *SendCoinTestSuite) SetupTest() {
 	}
 	s.db = db
 	userRepo := repo.NewUser(db)
-	coinRepo := repo.NewCoin(db)
-	userUC := usecase.NewUser(userRepo)
+	coinRepo := repo.NewCoin(db, zap.NewNop())
+	userUC := usecase.NewUser(userRepo, repo.NewLoginAttempt(db), entity.LockoutPolicy{}, false)
 	coinUC := usecase.NewCoin(coinRepo, userRepo)
-	s.handler = delivery.NewCoinHandler(coinUC, userUC)
+	s.handler = delivery.NewCoinHandler(coinUC, userUC, usecase.NewEscrow(repo.NewEscrow(db, zap.NewNop()), time.Hour, 10))
 	s.url = "/sendCoin"
 	s.fromUser = entity.User{
 		Name:  "sofia",
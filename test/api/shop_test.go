@@ -18,6 +18,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
 )
 
 var userKey string = "user"
@@ -55,9 +56,9 @@ func (s *ShopTestSuite) SetupTest() {
 	s.db = db
 	merchRepo := repo.NewMerch(db)
 	userRepo := repo.NewUser(db)
-	coinRepo := repo.NewCoin(db)
+	coinRepo := repo.NewCoin(db, zap.NewNop())
 	merchUC := usecase.NewMerch(merchRepo, coinRepo)
-	userUC := usecase.NewUser(userRepo)
+	userUC := usecase.NewUser(userRepo, repo.NewLoginAttempt(db), entity.LockoutPolicy{}, false)
 	coinUC := usecase.NewCoin(coinRepo, userRepo)
 	s.handler = delivery.NewShopHandler(merchUC, userUC, coinUC)
 	s.url = "/buy"
@@ -153,6 +154,38 @@ func (s *ShopTestSuite) TestBuyMerch_NotEnoughCoin() {
 	s.Equal(body, `{"error":"У вас недостаточно стредств"}`)
 }
 
+func (s *ShopTestSuite) TestBuyMerch_OutOfStock() {
+	query := `INSERT INTO "user" (name, password, coins) VALUES ($1, $2, $3)
+				RETURNING id, name, coins`
+	ctx := context.Background()
+	s.db.Exec(ctx, `DELETE FROM "user"`)
+	s.db.QueryRow(ctx, query, s.user.Name, "12345", s.user.Coins).Scan(&s.user.ID, &s.user.Name, &s.user.Coins)
+
+	merch := "pink-hoody"
+	var stock *int
+	s.db.QueryRow(ctx, `SELECT stock FROM merch WHERE name = $1`, merch).Scan(&stock)
+	s.db.Exec(ctx, `UPDATE merch SET stock = 0 WHERE name = $1`, merch)
+	defer s.db.Exec(ctx, `UPDATE merch SET stock = $1 WHERE name = $2`, stock, merch)
+
+	req := httptest.NewRequest("GET", s.url+"/"+merch, nil)
+	req = req.WithContext(context.WithValue(req.Context(), userKey, s.user))
+
+	rw := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc(s.url+"/{item}", s.handler.BuyMerch)
+	router.ServeHTTP(rw, req)
+
+	s.Equal(http.StatusConflict, rw.Code)
+	bodyBytes, err := io.ReadAll(rw.Body)
+	s.Equal(err, nil)
+	body := string(bodyBytes)
+	s.Equal(body, `{"error":"Мерч закончился"}`)
+
+	var balance uint32
+	s.db.QueryRow(ctx, `SELECT coins FROM "user" WHERE id = $1`, s.user.ID).Scan(&balance)
+	s.Equal(s.user.Coins, balance)
+}
+
 func (s *ShopTestSuite) TestBuyMerch_ServerError() {
 	query := `INSERT INTO "user" (name, password, coins) VALUES ($1, $2, $3)
 				RETURNING id, name, coins`